@@ -0,0 +1,312 @@
+package table
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/codec"
+	"github.com/caravan/streaming/table/config"
+)
+
+type (
+	// changelog is where a Table appends its Changes. It's backed by either
+	// a Topic or a local file
+	changelog[Key comparable, Value any] struct {
+		producer topic.Producer[*table.Change[Key, Value]]
+		file     *os.File
+		encoder  codec.Encoder
+		txns     uint64
+	}
+
+	// logRecord is an entry of a changelog file. The Changes written by a
+	// transaction carry its ID, and only take effect once a record that
+	// commits the ID follows them, so that a transaction that fails part
+	// way through its commit is discarded when the file is replayed. A
+	// Change without an ID takes effect immediately
+	logRecord[Key comparable, Value any] struct {
+		Change *table.Change[Key, Value]
+		Unset  []int `json:",omitempty"`
+		Txn    uint64
+		Commit bool
+	}
+
+	// compacted holds the last known row for each Key of a changelog
+	compacted[Key comparable, Value any] map[Key]*loaded[Value]
+
+	// loaded is a row that's being restored or replayed, along with the
+	// positions of its columns that have never been set
+	loaded[Value any] struct {
+		row   []Value
+		unset []int
+	}
+)
+
+func (t *Table[Key, Value]) openChangelog(cfg *config.Config) error {
+	switch {
+	case cfg.Changelog != nil:
+		top, ok := cfg.Changelog.(topic.Topic[*table.Change[Key, Value]])
+		if !ok {
+			return fmt.Errorf(config.ErrChangelogType, cfg.Changelog)
+		}
+		if err := t.load(replayTopic(top)); err != nil {
+			return err
+		}
+		t.changelog = &changelog[Key, Value]{
+			producer: top.NewProducer(),
+		}
+		return nil
+	case cfg.ChangelogFile != "":
+		rows, err := replayFile[Key, Value](t.codec, cfg.ChangelogFile)
+		if err != nil {
+			return err
+		}
+		if err := t.load(rows); err != nil {
+			return err
+		}
+		l, err := t.rewriteChangelog(cfg.ChangelogFile)
+		if err != nil {
+			return err
+		}
+		t.changelog = l
+		return nil
+	default:
+		return nil
+	}
+}
+
+// load adds the rows of a compacted changelog to the Table
+func (t *Table[Key, Value]) load(rows compacted[Key, Value]) error {
+	t.Lock()
+	defer t.Unlock()
+
+	now := time.Now()
+	for k, l := range rows {
+		if len(l.row) != len(t.names) {
+			return fmt.Errorf(
+				table.ErrValueCountRequired, len(t.names), len(l.row),
+			)
+		}
+		u := unsetAt(l.unset, len(t.names))
+		if err := t.loadRow(k, l.row, u, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadRow stores a row that's being restored or replayed, along with its
+// unset columns. The caller must hold the write lock
+func (t *Table[Key, Value]) loadRow(
+	k Key, row []Value, u unset, now time.Time,
+) error {
+	entries, err := t.indexEntries(row)
+	if err != nil {
+		return err
+	}
+	old, ok, err := t.peek(k)
+	if err != nil {
+		return err
+	}
+	err = t.storeRow(k, old, ok, t.sizeOf(k, old), row, entries, now)
+	if err != nil {
+		return err
+	}
+	t.storeUnset(k, u)
+	return nil
+}
+
+// rewriteChangelog replaces the changelog file with one that only contains
+// the Table's current rows, and leaves it open for appending. The compacted
+// file is written to a temporary file first, so that a failure never
+// clobbers the previous one
+func (t *Table[Key, Value]) rewriteChangelog(
+	path string,
+) (*changelog[Key, Value], error) {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return nil, err
+	}
+	l := &changelog[Key, Value]{
+		file:    f,
+		encoder: t.codec.NewEncoder(f),
+	}
+	err = func() error {
+		t.RLock()
+		defer t.RUnlock()
+		var err error
+		eachErr := t.rows.Each(func(k Key, row []Value) bool {
+			err = l.append(&table.Change[Key, Value]{
+				Operation: table.Inserted,
+				Key:       k,
+				New:       row,
+			}, t.unset[k])
+			return err == nil
+		})
+		if err == nil {
+			err = eachErr
+		}
+		return err
+	}()
+	if err == nil {
+		err = f.Sync()
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return nil, err
+	}
+	return l, nil
+}
+
+// append adds the Change to the changelog, along with the unset columns of
+// the row it leaves behind. A Topic only carries the Change, so rows that are
+// replayed from one are considered fully set
+func (l *changelog[Key, Value]) append(
+	c *table.Change[Key, Value], u unset,
+) error {
+	if l.producer != nil {
+		l.producer.Send() <- c
+		return nil
+	}
+	return l.write(c, u, 0)
+}
+
+// stage writes the Changes of a transaction without committing them,
+// returning the transaction's ID. A Topic can't take back what's sent to it,
+// so its Changes are only sent once they're committed
+func (l *changelog[Key, Value]) stage(
+	changes []*table.Change[Key, Value], unsetOf func(Key) unset,
+) (uint64, error) {
+	if l.producer != nil {
+		return 0, nil
+	}
+	l.txns++
+	for _, c := range changes {
+		if err := l.write(c, unsetOf(c.Key), l.txns); err != nil {
+			return 0, err
+		}
+	}
+	return l.txns, nil
+}
+
+// commit makes the staged Changes of a transaction take effect
+func (l *changelog[Key, Value]) commit(
+	txn uint64, changes []*table.Change[Key, Value],
+) error {
+	if l.producer != nil {
+		for _, c := range changes {
+			l.producer.Send() <- c
+		}
+		return nil
+	}
+	return l.encoder.Encode(&logRecord[Key, Value]{
+		Txn:    txn,
+		Commit: true,
+	})
+}
+
+// close closes the changelog's Producer, or flushes its file to disk and
+// closes it
+func (l *changelog[_, _]) close() error {
+	if l.producer != nil {
+		l.producer.Close()
+		return nil
+	}
+	err := l.file.Sync()
+	if cErr := l.file.Close(); err == nil {
+		err = cErr
+	}
+	return err
+}
+
+func (l *changelog[Key, Value]) write(
+	c *table.Change[Key, Value], u unset, txn uint64,
+) error {
+	// Replaying only requires the new state of each row
+	return l.encoder.Encode(&logRecord[Key, Value]{
+		Change: &table.Change[Key, Value]{
+			Operation: c.Operation,
+			Key:       c.Key,
+			New:       c.New,
+		},
+		Unset: u.positions(),
+		Txn:   txn,
+	})
+}
+
+// replayTopic consumes exactly as many Changes as the Topic's current Length
+func replayTopic[Key comparable, Value any](
+	t topic.Topic[*table.Change[Key, Value]],
+) compacted[Key, Value] {
+	res := compacted[Key, Value]{}
+	c := t.NewConsumer()
+	defer c.Close()
+	for i := t.Length(); i > 0; i-- {
+		res.apply(<-c.Receive(), nil)
+	}
+	return res
+}
+
+// replayFile decodes every Change in the file. A partially written Change at
+// the end of the file, such as one left by a crash, is ignored, as are the
+// Changes of transactions that were never committed
+func replayFile[Key comparable, Value any](
+	c codec.Codec, path string,
+) (compacted[Key, Value], error) {
+	res := compacted[Key, Value]{}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return res, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	staged := map[uint64][]*logRecord[Key, Value]{}
+	dec := c.NewDecoder(f)
+	for {
+		rec := &logRecord[Key, Value]{}
+		err := dec.Decode(rec)
+		if err == io.EOF || errors.Is(err, io.ErrUnexpectedEOF) {
+			return res, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case rec.Commit:
+			for _, s := range staged[rec.Txn] {
+				res.apply(s.Change, s.Unset)
+			}
+			delete(staged, rec.Txn)
+		case rec.Txn != 0:
+			staged[rec.Txn] = append(staged[rec.Txn], rec)
+		default:
+			res.apply(rec.Change, rec.Unset)
+		}
+	}
+}
+
+func (c compacted[Key, Value]) apply(
+	change *table.Change[Key, Value], unset []int,
+) {
+	switch change.Operation {
+	case table.Inserted, table.Updated:
+		c[change.Key] = &loaded[Value]{
+			row:   change.New,
+			unset: unset,
+		}
+	default:
+		delete(c, change.Key)
+	}
+}
@@ -0,0 +1,218 @@
+package table_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/caravan/essentials"
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/codec"
+	"github.com/caravan/streaming/table/config"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/streaming/internal/table"
+)
+
+func TestTopicChangelog(t *testing.T) {
+	as := assert.New(t)
+
+	cols := []table.ColumnName{"name", "age"}
+	log := essentials.NewTopic[*table.Change[string, any]]()
+	tbl, err := internal.MakeWith[string, any](cols, config.Changelog(log))
+	as.NotNil(tbl)
+	as.Nil(err)
+
+	setter, _ := tbl.Setter("name", "age")
+	ageSetter, _ := tbl.Setter("age")
+	as.Nil(setter("1", "bob", 42))
+	as.Nil(setter("2", "june", 36))
+	as.Nil(ageSetter("1", 43))
+	as.Nil(tbl.Deleter()("2"))
+
+	c := log.NewConsumer()
+	as.Equal(table.Inserted, (<-c.Receive()).Operation)
+	as.Equal(table.Inserted, (<-c.Receive()).Operation)
+	as.Equal(&table.Change[string, any]{
+		Operation: table.Updated,
+		Key:       "1",
+		Old:       []any{"bob", 42},
+		New:       []any{"bob", 43},
+	}, <-c.Receive())
+	as.Equal(table.Deleted, (<-c.Receive()).Operation)
+	c.Close()
+
+	rebuilt, err := internal.MakeWith[string, any](cols, config.Changelog(log))
+	as.Nil(err)
+	getter, _ := rebuilt.Getter("name", "age")
+	res, _ := getter("1")
+	as.Equal([]any{"bob", 43}, res)
+	_, err = getter("2")
+	as.EqualError(err, fmt.Sprintf(table.ErrKeyNotFound, "2"))
+}
+
+func TestBadTopicChangelog(t *testing.T) {
+	as := assert.New(t)
+
+	log := essentials.NewTopic[*table.Change[int, any]]()
+	tbl, err := internal.MakeWith[string, any](
+		[]table.ColumnName{"name"}, config.Changelog(log),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, fmt.Sprintf(config.ErrChangelogType, log))
+
+	wide := essentials.NewTopic[*table.Change[string, any]]()
+	p := wide.NewProducer()
+	p.Send() <- &table.Change[string, any]{
+		Key: "1",
+		New: []any{"bob", 42},
+	}
+	p.Close()
+
+	tbl, err = internal.MakeWith[string, any](
+		[]table.ColumnName{"name"}, config.Changelog(wide),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, fmt.Sprintf(table.ErrValueCountRequired, 1, 2))
+}
+
+func TestFileChangelog(t *testing.T) {
+	for _, cc := range []codec.Codec{codec.Gob, codec.JSON} {
+		as := assert.New(t)
+
+		path := filepath.Join(t.TempDir(), "table.changelog")
+		cols := []table.ColumnName{"name", "age"}
+		open := func() table.Table[string, string] {
+			tbl, err := internal.MakeWith[string, string](cols,
+				config.ChangelogFile(path), config.Codec(cc),
+			)
+			as.Nil(err)
+			return tbl
+		}
+
+		tbl := open()
+		setter, _ := tbl.Setter("name", "age")
+		for i := 0; i < 100; i++ {
+			as.Nil(setter("1", "bob", fmt.Sprint(i)))
+		}
+		as.Nil(setter("2", "june", "36"))
+		as.Nil(setter("3", "carol", "47"))
+		as.Nil(tbl.Deleter()("3"))
+
+		before, _ := os.Stat(path)
+		rebuilt := open()
+		after, _ := os.Stat(path)
+		as.Less(after.Size(), before.Size())
+
+		getter, _ := rebuilt.Getter("name", "age")
+		res, _ := getter("1")
+		as.Equal([]string{"bob", "99"}, res)
+		res, _ = getter("2")
+		as.Equal([]string{"june", "36"}, res)
+		_, err := getter("3")
+		as.EqualError(err, fmt.Sprintf(table.ErrKeyNotFound, "3"))
+
+		// Appends made after a rebuild are replayed by the next one
+		setter, _ = rebuilt.Setter("name", "age")
+		as.Nil(setter("4", "dave", "51"))
+		getter, _ = open().Getter("name", "age")
+		res, _ = getter("4")
+		as.Equal([]string{"dave", "51"}, res)
+	}
+}
+
+func TestTornFileChangelog(t *testing.T) {
+	as := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "table.changelog")
+	cols := []table.ColumnName{"name"}
+	tbl, _ := internal.MakeWith[string, string](cols,
+		config.ChangelogFile(path), config.Codec(codec.JSON),
+	)
+	setter, _ := tbl.Setter("name")
+	as.Nil(setter("1", "bob"))
+
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	_, _ = f.WriteString(`{"Change":{"Operation":0,"Key":"2","New":["ju`)
+	_ = f.Close()
+
+	rebuilt, err := internal.MakeWith[string, string](cols,
+		config.ChangelogFile(path), config.Codec(codec.JSON),
+	)
+	as.Nil(err)
+	getter, _ := rebuilt.Getter("name")
+	res, _ := getter("1")
+	as.Equal([]string{"bob"}, res)
+	_, err = getter("2")
+	as.EqualError(err, fmt.Sprintf(table.ErrKeyNotFound, "2"))
+}
+
+func TestUncommittedFileChangelog(t *testing.T) {
+	as := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "table.changelog")
+	cols := []table.ColumnName{"name"}
+	tbl, _ := internal.MakeWith[string, string](cols,
+		config.ChangelogFile(path), config.Codec(codec.JSON),
+	)
+	tx := tbl.Begin()
+	setter, _ := tx.Setter("name")
+	as.Nil(setter("1", "bob"))
+	as.Nil(setter("2", "june"))
+	as.Nil(tx.Commit())
+
+	// A transaction whose commit was interrupted after staging its Changes
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	_, _ = f.WriteString(
+		`{"Change":{"Operation":1,"Key":"1","New":["bill"]},"Txn":9}` + "\n" +
+			`{"Change":{"Operation":0,"Key":"3","New":["jane"]},"Txn":9}` + "\n",
+	)
+	_ = f.Close()
+
+	rebuilt, err := internal.MakeWith[string, string](cols,
+		config.ChangelogFile(path), config.Codec(codec.JSON),
+	)
+	as.Nil(err)
+	as.Equal(2, rebuilt.Len())
+	getter, _ := rebuilt.Getter("name")
+	res, _ := getter("1")
+	as.Equal([]string{"bob"}, res)
+	_, err = getter("3")
+	as.EqualError(err, fmt.Sprintf(table.ErrKeyNotFound, "3"))
+}
+
+func TestCloseFileChangelog(t *testing.T) {
+	as := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "table.changelog")
+	cols := []table.ColumnName{"name"}
+	tbl, _ := internal.MakeWith[string, string](cols,
+		config.ChangelogFile(path),
+	)
+	setter, _ := tbl.Setter("name")
+	as.Nil(setter("1", "bob"))
+	as.Nil(tbl.Close())
+	as.Nil(tbl.Close())
+	as.ErrorIs(setter("2", "june"), os.ErrClosed)
+
+	rebuilt, err := internal.MakeWith[string, string](cols,
+		config.ChangelogFile(path),
+	)
+	as.Nil(err)
+	as.Equal(1, rebuilt.Len())
+	as.Nil(rebuilt.Close())
+}
+
+func TestChangelogConflict(t *testing.T) {
+	as := assert.New(t)
+
+	log := essentials.NewTopic[*table.Change[string, any]]()
+	tbl, err := internal.MakeWith[string, any](
+		[]table.ColumnName{"name"},
+		config.ChangelogFile("some file"),
+		config.Changelog(log),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, config.ErrChangelogAlreadySet)
+}
@@ -0,0 +1,89 @@
+package table
+
+import (
+	"github.com/caravan/essentials"
+	"github.com/caravan/essentials/closer"
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/streaming/table"
+
+	topicConfig "github.com/caravan/essentials/topic/config"
+)
+
+// watcher pairs the Consumer handed out by Changes with the Producer that the
+// Table publishes to. Each watcher has its own Topic, so that a new Consumer
+// never sees Changes that were made before it was created
+type watcher[Key comparable, Value any] struct {
+	producer topic.Producer[*table.Change[Key, Value]]
+	consumer topic.Consumer[*table.Change[Key, Value]]
+}
+
+func (t *Table[Key, Value]) Changes() topic.Consumer[*table.Change[Key, Value]] {
+	top := essentials.NewTopic[*table.Change[Key, Value]](
+		topicConfig.Consumed,
+	)
+	c := top.NewConsumer()
+	t.watch(&watcher[Key, Value]{
+		producer: top.NewProducer(),
+		consumer: c,
+	})
+	return c
+}
+
+// watch starts publishing the Table's Changes to the watcher. A closed Table
+// has nothing more to publish, so the watcher is closed right away
+func (t *Table[Key, Value]) watch(w *watcher[Key, Value]) {
+	t.Lock()
+	defer t.Unlock()
+	if t.closed {
+		w.close()
+		return
+	}
+	t.watchers = append(t.watchers, w)
+}
+
+// close ends the watcher's Consumer, so that it sees its source end
+func (w *watcher[_, _]) close() {
+	w.producer.Close()
+	w.consumer.Close()
+}
+
+// isWatched reports whether anyone might be interested in the Table's
+// Changes. The caller must hold at least the read lock
+func (t *Table[_, _]) isWatched() bool {
+	return len(t.watchers) != 0 || t.changelog != nil
+}
+
+// record appends the Change, along with the unset columns of the row it
+// leaves behind, to the changelog and publishes it to every watcher. If the
+// changelog can't be appended to, nothing is published and the caller must
+// abandon the Change. The caller must hold the write lock
+func (t *Table[Key, Value]) record(
+	c *table.Change[Key, Value], u unset,
+) error {
+	if t.changelog != nil {
+		if err := t.changelog.append(c, u); err != nil {
+			return err
+		}
+	}
+	t.publish(c)
+	return nil
+}
+
+// publish sends the Change to every watcher, discarding those whose Consumer
+// has been closed. The caller must hold the write lock, which guarantees that
+// every watcher sees Changes in the order they were made
+func (t *Table[Key, Value]) publish(c *table.Change[Key, Value]) {
+	live := t.watchers[:0]
+	for _, w := range t.watchers {
+		if closer.IsClosed(w.consumer) {
+			w.producer.Close()
+			continue
+		}
+		w.producer.Send() <- c
+		live = append(live, w)
+	}
+	for i := len(live); i < len(t.watchers); i++ {
+		t.watchers[i] = nil
+	}
+	t.watchers = live
+}
@@ -0,0 +1,101 @@
+package table_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caravan/essentials/message"
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/config"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/streaming/internal/table"
+)
+
+func TestChanges(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.Make[string, any]("name", "age")
+	setter, _ := tbl.Setter("name", "age")
+	ageSetter, _ := tbl.Setter("age")
+	deleter := tbl.Deleter()
+
+	as.Nil(setter("0", "before", 1)) // not seen by the consumer
+
+	c := tbl.Changes()
+	as.Nil(setter("1", "bob", 42))
+	as.Nil(ageSetter("1", 43))
+	as.Nil(deleter("1"))
+
+	as.Equal(&table.Change[string, any]{
+		Operation: table.Inserted,
+		Key:       "1",
+		New:       []any{"bob", 42},
+	}, <-c.Receive())
+	as.Equal(&table.Change[string, any]{
+		Operation: table.Updated,
+		Key:       "1",
+		Old:       []any{"bob", 42},
+		New:       []any{"bob", 43},
+	}, <-c.Receive())
+	as.Equal(&table.Change[string, any]{
+		Operation: table.Deleted,
+		Key:       "1",
+		Old:       []any{"bob", 43},
+	}, <-c.Receive())
+
+	c.Close()
+	as.Nil(setter("2", "june", 36)) // discards the closed consumer
+}
+
+func TestExpiredChanges(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.MakeWith[string, any](
+		[]table.ColumnName{"name"},
+		config.TTL(10*time.Millisecond),
+		config.SweepInterval(5*time.Millisecond),
+	)
+	setter, _ := tbl.Setter("name")
+
+	c := tbl.Changes()
+	as.Nil(setter("1", "bob"))
+	as.Equal(table.Inserted, (<-c.Receive()).Operation)
+
+	change, ok := message.Poll[*table.Change[string, any]](c, time.Second)
+	as.True(ok)
+	as.Equal(&table.Change[string, any]{
+		Operation: table.Expired,
+		Key:       "1",
+		Old:       []any{"bob"},
+	}, change)
+	c.Close()
+}
+
+func TestChangesClosed(t *testing.T) {
+	as := assert.New(t)
+
+	for _, shards := range []int{1, 4} {
+		tbl, _ := internal.MakeWith[string, any](
+			[]table.ColumnName{"name"}, config.Shards(shards),
+		)
+		c := tbl.Changes()
+		as.Nil(tbl.Close())
+		_, ok := <-c.Receive()
+		as.False(ok)
+		c.Close()
+
+		// Watching a closed Table ends right away
+		_, ok = <-tbl.Changes().Receive()
+		as.False(ok)
+	}
+}
+
+func TestOperationString(t *testing.T) {
+	as := assert.New(t)
+	as.Equal("inserted", table.Inserted.String())
+	as.Equal("updated", table.Updated.String())
+	as.Equal("deleted", table.Deleted.String())
+	as.Equal("expired", table.Expired.String())
+	as.Equal("unknown", table.Operation(99).String())
+}
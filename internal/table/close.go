@@ -0,0 +1,57 @@
+package table
+
+// Close stops the Table's expiry sweeper and automatic snapshots, writes a
+// final snapshot if there are writes that haven't been captured by one, and
+// then flushes its changelog and Store, releasing the resources they hold.
+// Every Consumer returned by Changes is closed, discarding the Changes it
+// hasn't received. The first failure of the Table's background work is
+// reported if nothing else fails. Only the first call has any effect
+func (t *Table[_, _]) Close() error {
+	t.Lock()
+	if t.closed {
+		t.Unlock()
+		return nil
+	}
+	t.closed = true
+	t.stopSweeping()
+	t.stopSnapshots()
+	t.Unlock()
+
+	var res error
+	if p := t.persist; p != nil {
+		// Waits for an automatic snapshot that's already being written
+		p.Lock()
+		defer p.Unlock()
+		if t.isSnapshotPending() {
+			res = t.snapshotFile()
+		}
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	for _, w := range t.watchers {
+		w.close()
+	}
+	t.watchers = nil
+	if t.changelog != nil {
+		if err := t.changelog.close(); res == nil {
+			res = err
+		}
+	}
+	if err := t.rows.Close(); res == nil {
+		res = err
+	}
+	if res == nil {
+		res = t.failure
+	}
+	return res
+}
+
+// fail records the first failure of the Table's background work, or of any
+// other work that has nobody to return it to, so that it can be reported by
+// Close. The caller must hold the write lock
+func (t *Table[_, _]) fail(err error) {
+	if t.failure == nil {
+		t.failure = err
+	}
+}
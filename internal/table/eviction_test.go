@@ -0,0 +1,251 @@
+package table_test
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/config"
+	"github.com/caravan/streaming/table/store"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/streaming/internal/table"
+)
+
+func TestMaxRows(t *testing.T) {
+	as := assert.New(t)
+
+	var evicted []string
+	tbl, err := internal.MakeWith[string, any](
+		[]table.ColumnName{"name"},
+		config.MaxRows(2),
+		config.OnEvict(func(k string, row []any) {
+			evicted = append(evicted, k)
+		}),
+	)
+	as.Nil(err)
+
+	setter, _ := tbl.Setter("name")
+	getter, _ := tbl.Getter("name")
+	as.Nil(setter("1", "bob"))
+	as.Nil(setter("2", "june"))
+	_, _ = getter("1")
+	as.Nil(setter("3", "frank"))
+	as.Equal([]string{"2"}, evicted)
+	as.Equal(2, tbl.Len())
+
+	_, err = getter("2")
+	as.EqualError(err, fmt.Sprintf(table.ErrKeyNotFound, "2"))
+	as.Equal(table.Lookups{Hits: 1, Misses: 1}, stats(t, tbl).Lookups)
+
+	// The handler is called outside the lock, so it may use the Table
+	var lens []int
+	tbl, _ = internal.MakeWith[string, any](
+		[]table.ColumnName{"name"},
+		config.MaxRows(1),
+		config.OnEvict(func(string, []any) {
+			lens = append(lens, tbl.Len())
+		}),
+	)
+	setter, _ = tbl.Setter("name")
+	as.Nil(setter("1", "bob"))
+	as.Nil(setter("2", "june"))
+	as.Equal([]int{1}, lens)
+}
+
+func TestEvictionPolicy(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := internal.MakeWith[string, any](
+		[]table.ColumnName{"name"},
+		config.MaxRows(2),
+		config.Eviction(store.LeastFrequentlyUsed),
+	)
+	as.Nil(err)
+
+	setter, _ := tbl.Setter("name")
+	getter, _ := tbl.Getter("name")
+	as.Nil(setter("1", "bob"))
+	as.Nil(setter("2", "june"))
+	_, _ = getter("1")
+	_, _ = getter("2")
+	_, _ = getter("2")
+	as.Nil(setter("3", "frank"))
+
+	keys, err := tbl.Keys()
+	as.Nil(err)
+	sort.Strings(keys)
+	as.Equal([]string{"2", "3"}, keys)
+}
+
+func TestEvictionPolicyWrites(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := internal.MakeWith[string, any](
+		[]table.ColumnName{"name"},
+		config.MaxRows(2),
+		config.Eviction(store.LeastFrequentlyUsed),
+	)
+	as.Nil(err)
+
+	// 1 is written three times, while 2 is written once and read three
+	// times, so only 2's reads make it the more frequently used
+	setter, _ := tbl.Setter("name")
+	getter, _ := tbl.Getter("name")
+	as.Nil(setter("1", "bob"))
+	as.Nil(setter("1", "robert"))
+	as.Nil(setter("1", "bobby"))
+	as.Nil(setter("2", "june"))
+	for i := 0; i < 3; i++ {
+		_, err = getter("2")
+		as.Nil(err)
+	}
+	as.Nil(setter("3", "frank"))
+
+	keys, err := tbl.Keys()
+	as.Nil(err)
+	sort.Strings(keys)
+	as.Equal([]string{"2", "3"}, keys)
+
+	// The same goes for writes made by Transactions
+	for _, name := range []string{"francis", "frankie"} {
+		tx := tbl.Begin()
+		txSetter, _ := tx.Setter("name")
+		as.Nil(txSetter("3", name))
+		as.Nil(tx.Commit())
+	}
+	as.Nil(setter("4", "alice"))
+
+	keys, err = tbl.Keys()
+	as.Nil(err)
+	sort.Strings(keys)
+	as.Equal([]string{"2", "4"}, keys)
+}
+
+func TestMaxMemory(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := internal.MakeWith[int, string](
+		[]table.ColumnName{"payload"},
+		config.MaxMemory(4096),
+	)
+	as.Nil(err)
+
+	setter, _ := tbl.Setter("payload")
+	for i := 0; i < 100; i++ {
+		as.Nil(setter(i, strings.Repeat("x", 100)))
+	}
+	as.Less(tbl.Len(), 100)
+	as.Greater(tbl.Len(), 10)
+
+	getter, _ := tbl.Getter("payload")
+	_, err = getter(99)
+	as.Nil(err)
+	_, err = getter(0)
+	as.NotNil(err)
+}
+
+func TestBoundedTransaction(t *testing.T) {
+	as := assert.New(t)
+
+	var mu sync.Mutex
+	var evicted []string
+	tbl, _ := internal.MakeWith[string, any](
+		[]table.ColumnName{"name"},
+		config.MaxRows(1),
+		config.OnEvict(func(k string, _ []any) {
+			mu.Lock()
+			defer mu.Unlock()
+			evicted = append(evicted, k)
+		}),
+	)
+
+	tx := tbl.Begin()
+	setter, _ := tx.Setter("name")
+	as.Nil(setter("1", "bob"))
+	as.Nil(setter("2", "june"))
+	as.Nil(tx.Commit())
+	as.Equal([]string{"1"}, evicted)
+	keys, err := tbl.Keys()
+	as.Nil(err)
+	as.Equal([]string{"2"}, keys)
+}
+
+func TestShardedBounds(t *testing.T) {
+	as := assert.New(t)
+
+	var mu sync.Mutex
+	evicted := 0
+	tbl, err := internal.MakeWith[int, any](
+		[]table.ColumnName{"value"},
+		config.Shards(4),
+		config.MaxRows(8),
+		config.OnEvict(func(int, []any) {
+			mu.Lock()
+			defer mu.Unlock()
+			evicted++
+		}),
+	)
+	as.Nil(err)
+
+	setter, _ := tbl.Setter("value")
+	getter, _ := tbl.Getter("value")
+	for i := 0; i < 100; i++ {
+		as.Nil(setter(i, i))
+	}
+	as.LessOrEqual(tbl.Len(), 8)
+	as.Equal(100-tbl.Len(), evicted)
+
+	for i := 0; i < 100; i++ {
+		_, _ = getter(i)
+	}
+	l := stats(t, tbl).Lookups
+	as.Equal(uint64(tbl.Len()), l.Hits)
+	as.Equal(uint64(100-tbl.Len()), l.Misses)
+}
+
+func TestBoundsErrors(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := internal.MakeWith[string, any](
+		[]table.ColumnName{"name"},
+		config.Eviction(store.LeastFrequentlyUsed),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, config.ErrBoundRequired)
+
+	tbl, err = internal.MakeWith[string, any](
+		[]table.ColumnName{"name"},
+		config.MaxRows(10),
+		config.Store(store.Map[string, any]()),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, config.ErrBoundedStore)
+
+	tbl, err = internal.MakeWith[string, any](
+		[]table.ColumnName{"name"},
+		config.OnEvict(func(string, []any) {}),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, config.ErrEvictingStore)
+
+	handler := func(int, []any) {}
+	tbl, err = internal.MakeWith[string, any](
+		[]table.ColumnName{"name"},
+		config.MaxRows(10),
+		config.OnEvict(handler),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, fmt.Sprintf(
+		config.ErrEvictHandlerType, config.EvictHandler[int, any](handler),
+	))
+
+	ord, err := internal.MakeOrdered[string, any](
+		[]table.ColumnName{"name"}, config.MaxRows(10),
+	)
+	as.Nil(ord)
+	as.EqualError(err, config.ErrOrderedBounds)
+}
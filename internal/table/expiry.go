@@ -0,0 +1,265 @@
+package table
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/config"
+)
+
+type (
+	// expiry tracks the deadlines of a Table's rows. Because every row
+	// lives for the same TTL, deadlines are kept in write order, and the
+	// soonest to expire is always at the front of the list
+	expiry[Key comparable, Value any] struct {
+		ttl       time.Duration
+		interval  time.Duration
+		onExpire  config.ExpireHandler[Key, Value]
+		deadlines *list.List
+		elements  map[Key]*list.Element
+		sweeping  bool
+		stop      chan struct{}
+	}
+
+	deadline[Key comparable] struct {
+		key Key
+		at  time.Time
+	}
+
+	// removal is a row that the Table removed on its own, because it
+	// either expired or was evicted. Handlers are notified of removals once
+	// the lock has been released
+	removal[Key comparable, Value any] struct {
+		op  table.Operation
+		key Key
+		row []Value
+	}
+)
+
+// sweepBatchSize is the most rows a sweep will expire while holding the lock
+const sweepBatchSize = 256
+
+func makeExpiry[Key comparable, Value any](
+	cfg *config.Config,
+) (*expiry[Key, Value], error) {
+	var onExpire config.ExpireHandler[Key, Value]
+	if cfg.OnExpire != nil {
+		h, ok := cfg.OnExpire.(config.ExpireHandler[Key, Value])
+		if !ok {
+			return nil, fmt.Errorf(config.ErrExpireHandlerType, cfg.OnExpire)
+		}
+		onExpire = h
+	}
+	if cfg.TTL == 0 {
+		if onExpire != nil {
+			return nil, errors.New(config.ErrTTLRequired)
+		}
+		return nil, nil
+	}
+	return &expiry[Key, Value]{
+		ttl:       cfg.TTL,
+		interval:  cfg.SweepInterval,
+		onExpire:  onExpire,
+		deadlines: list.New(),
+		elements:  map[Key]*list.Element{},
+		stop:      make(chan struct{}),
+	}, nil
+}
+
+// isExpired reports whether the row for the Key has outlived the Table's TTL.
+// The caller must hold at least the read lock
+func (t *Table[Key, _]) isExpired(k Key, now time.Time) bool {
+	if t.expiry == nil {
+		return false
+	}
+	if e, ok := t.expiry.elements[k]; ok {
+		return !now.Before(e.Value.(*deadline[Key]).at)
+	}
+	return false
+}
+
+// countExpired returns the number of rows that have outlived the Table's TTL
+// but haven't been swept yet. The caller must hold at least the read lock
+func (t *Table[Key, _]) countExpired(now time.Time) int {
+	if t.expiry == nil {
+		return 0
+	}
+	res := 0
+	for e := t.expiry.deadlines.Front(); e != nil; e = e.Next() {
+		if now.Before(e.Value.(*deadline[Key]).at) {
+			break
+		}
+		res++
+	}
+	return res
+}
+
+// touch resets the deadline for the row of the Key. The caller must hold the
+// write lock
+func (t *Table[Key, _]) touch(k Key, now time.Time) {
+	exp := t.expiry
+	if exp == nil {
+		return
+	}
+	at := now.Add(exp.ttl)
+	if e, ok := exp.elements[k]; ok {
+		e.Value.(*deadline[Key]).at = at
+		exp.deadlines.MoveToBack(e)
+	} else {
+		exp.elements[k] = exp.deadlines.PushBack(&deadline[Key]{
+			key: k,
+			at:  at,
+		})
+	}
+	t.startSweeping()
+}
+
+// forget stops tracking the deadline for the row of the Key. The caller must
+// hold the write lock
+func (t *Table[Key, _]) forget(k Key) {
+	exp := t.expiry
+	if exp == nil {
+		return
+	}
+	if e, ok := exp.elements[k]; ok {
+		exp.deadlines.Remove(e)
+		delete(exp.elements, k)
+	}
+}
+
+// expireIfDue removes the row of the Key if it has outlived the Table's TTL,
+// returning it for notification. The caller must hold the write lock
+func (t *Table[Key, Value]) expireIfDue(
+	k Key, now time.Time,
+) ([]*removal[Key, Value], error) {
+	if !t.isExpired(k, now) {
+		return nil, nil
+	}
+	r, err := t.expire(k)
+	return []*removal[Key, Value]{r}, err
+}
+
+// expire removes the row of the Key because it has outlived the Table's TTL.
+// The row is removed even if a step fails, because it must stop being
+// tracked regardless, or it would be expired over and over. The first
+// failure is returned
+func (t *Table[Key, Value]) expire(k Key) (*removal[Key, Value], error) {
+	row, _, res := t.peek(k)
+	if t.isWatched() {
+		// If the row is replayed from the changelog after a failure, it
+		// will simply expire again
+		err := t.record(&table.Change[Key, Value]{
+			Operation: table.Expired,
+			Key:       k,
+			Old:       row,
+		}, nil)
+		if res == nil {
+			res = err
+		}
+	}
+	if err := t.rows.Remove(k); res == nil {
+		res = err
+	}
+	t.untrack(k, row)
+	t.markDirty()
+	return &removal[Key, Value]{
+		op:  table.Expired,
+		key: k,
+		row: row,
+	}, res
+}
+
+// notifyRemoved hands expired rows to the expire handler, and evicted rows to
+// the evict handler. It must be called without holding the lock
+func (t *Table[Key, Value]) notifyRemoved(rem []*removal[Key, Value]) {
+	for _, r := range rem {
+		switch {
+		case r.op == table.Expired && t.expiry.onExpire != nil:
+			t.expiry.onExpire(r.key, r.row)
+		case r.op == table.Evicted && t.onEvict != nil:
+			t.onEvict(r.key, r.row)
+		}
+	}
+}
+
+// startSweeping kicks off the background sweeper if it isn't already running.
+// The sweeper exits once there are no rows left to expire, or once the Table
+// is closed, so an idle Table holds no goroutines. The caller must hold the
+// write lock
+func (t *Table[_, _]) startSweeping() {
+	if t.expiry.sweeping || t.closed {
+		return
+	}
+	t.expiry.sweeping = true
+	go func() {
+		tick := time.NewTicker(t.expiry.interval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-t.expiry.stop:
+				return
+			case <-tick.C:
+				if !t.sweep() {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// stopSweeping stops the background sweeper, if it's running. The caller must
+// hold the write lock
+func (t *Table[_, _]) stopSweeping() {
+	if t.expiry != nil {
+		close(t.expiry.stop)
+	}
+}
+
+// sweep expires rows in small batches, so the lock is never held for long.
+// It returns false when there are no rows left to track
+func (t *Table[_, _]) sweep() bool {
+	for {
+		exp, more := t.sweepBatch(time.Now())
+		t.notifyRemoved(exp)
+		if !more {
+			break
+		}
+	}
+
+	t.Lock()
+	defer t.Unlock()
+	if t.expiry.deadlines.Len() == 0 || t.closed {
+		t.expiry.sweeping = false
+		return false
+	}
+	return true
+}
+
+func (t *Table[Key, Value]) sweepBatch(
+	now time.Time,
+) ([]*removal[Key, Value], bool) {
+	t.Lock()
+	defer t.Unlock()
+
+	if t.closed {
+		return nil, false
+	}
+	var res []*removal[Key, Value]
+	for len(res) < sweepBatchSize {
+		front := t.expiry.deadlines.Front()
+		if front == nil {
+			return res, false
+		}
+		d := front.Value.(*deadline[Key])
+		if now.Before(d.at) {
+			return res, false
+		}
+		r, err := t.expire(d.key)
+		t.fail(err)
+		res = append(res, r)
+	}
+	return res, true
+}
@@ -0,0 +1,155 @@
+package table_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/config"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/streaming/internal/table"
+)
+
+func TestExpiry(t *testing.T) {
+	as := assert.New(t)
+
+	var lock sync.Mutex
+	expired := map[string][]any{}
+
+	tbl, err := internal.MakeWith[string, any](
+		[]table.ColumnName{"name", "age"},
+		config.TTL(50*time.Millisecond),
+		config.SweepInterval(5*time.Millisecond),
+		config.OnExpire(func(k string, row []any) {
+			lock.Lock()
+			defer lock.Unlock()
+			expired[k] = row
+		}),
+	)
+	as.NotNil(tbl)
+	as.Nil(err)
+
+	setter, _ := tbl.Setter("name", "age")
+	ageSetter, _ := tbl.Setter("age")
+	getter, _ := tbl.Getter("name", "age")
+
+	as.Nil(setter("1", "bob", 42))
+	as.Nil(setter("2", "june", 36))
+
+	time.Sleep(30 * time.Millisecond)
+	as.Nil(ageSetter("2", 37)) // keeps the second row alive
+
+	time.Sleep(30 * time.Millisecond)
+	res, err := getter("1")
+	as.Nil(res)
+	as.EqualError(err, fmt.Sprintf(table.ErrKeyNotFound, "1"))
+
+	res, err = getter("2")
+	as.Nil(err)
+	as.Equal([]any{"june", 37}, res)
+
+	lock.Lock()
+	as.Equal(map[string][]any{"1": {"bob", 42}}, expired)
+	lock.Unlock()
+
+	time.Sleep(50 * time.Millisecond)
+	_, err = getter("2")
+	as.EqualError(err, fmt.Sprintf(table.ErrKeyNotFound, "2"))
+
+	lock.Lock()
+	as.Equal(map[string][]any{
+		"1": {"bob", 42},
+		"2": {"june", 37},
+	}, expired)
+	lock.Unlock()
+}
+
+func TestExpiredBeforeSweep(t *testing.T) {
+	as := assert.New(t)
+
+	var lock sync.Mutex
+	var expired []string
+
+	tbl, _ := internal.MakeWith[string, any](
+		[]table.ColumnName{"name", "age"},
+		config.TTL(10*time.Millisecond),
+		config.SweepInterval(time.Hour),
+		config.OnExpire(func(k string, _ []any) {
+			lock.Lock()
+			defer lock.Unlock()
+			expired = append(expired, k)
+		}),
+	)
+
+	setter, _ := tbl.Setter("name", "age")
+	nameSetter, _ := tbl.Setter("name")
+	getter, _ := tbl.Getter("name", "age")
+	deleter := tbl.Deleter()
+
+	as.Nil(setter("1", "bob", 42))
+	as.Nil(setter("2", "june", 36))
+	time.Sleep(20 * time.Millisecond)
+
+	_, err := getter("1")
+	as.EqualError(err, fmt.Sprintf(table.ErrKeyNotFound, "1"))
+
+	// A write to an expired row starts a fresh one
+	as.Nil(nameSetter("1", "robert"))
+	res, _ := getter("1")
+	as.Equal([]any{"robert", nil}, res)
+
+	as.Nil(deleter("2"))
+
+	lock.Lock()
+	as.Equal([]string{"1", "2"}, expired)
+	lock.Unlock()
+}
+
+func TestCloseStopsSweeper(t *testing.T) {
+	as := assert.New(t)
+
+	var lock sync.Mutex
+	expired := 0
+	tbl, _ := internal.MakeWith[string, any](
+		[]table.ColumnName{"name"},
+		config.TTL(10*time.Millisecond),
+		config.SweepInterval(time.Millisecond),
+		config.OnExpire(func(string, []any) {
+			lock.Lock()
+			defer lock.Unlock()
+			expired++
+		}),
+	)
+	setter, _ := tbl.Setter("name")
+	as.Nil(setter("1", "bob"))
+	as.Nil(tbl.Close())
+
+	time.Sleep(30 * time.Millisecond)
+	lock.Lock()
+	as.Equal(0, expired)
+	lock.Unlock()
+}
+
+func TestBadExpiry(t *testing.T) {
+	as := assert.New(t)
+	cols := []table.ColumnName{"name"}
+
+	tbl, err := internal.MakeWith[string, any](cols,
+		config.OnExpire(func(string, []any) {}),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, config.ErrTTLRequired)
+
+	handler := func(int, []any) {}
+	tbl, err = internal.MakeWith[string, any](cols,
+		config.TTL(time.Second),
+		config.OnExpire(handler),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, fmt.Sprintf(
+		config.ErrExpireHandlerType, config.ExpireHandler[int, any](handler),
+	))
+}
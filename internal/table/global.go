@@ -0,0 +1,113 @@
+package table
+
+import (
+	"errors"
+
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/essentials/topic/retention"
+	"github.com/caravan/streaming/table"
+)
+
+type (
+	// global is a View whose rows are the messages of a Topic, applied to
+	// its Table by an Updater
+	global[Msg any, Key comparable, Value any] struct {
+		table.Table[Key, Value]
+		updater  table.Updater[Msg, Key, Value]
+		consumer topic.Consumer[Msg]
+		follower
+	}
+
+	// offsetReader is implemented by Topics that can read the message at an
+	// Offset, returning the Offset actually read if the requested one is no
+	// longer retained
+	offsetReader[Msg any] interface {
+		Get(retention.Offset) (Msg, retention.Offset, bool)
+	}
+)
+
+// MakeGlobal instantiates a View that applies every message of the Topic to
+// the Table using the provided Updater, which must update that Table. It
+// doesn't return until the messages that were in the Topic when it was called
+// have been applied, so the View is fully populated before anything reads it.
+// The Topic must retain every message it's ever been sent, as the View
+// consumes exactly as many messages as the Topic's Length before returning.
+// A Topic that reports having discarded some of them is rejected, but one
+// that discards them while they're being consumed can't be detected. If any
+// of those messages can't be applied, the View isn't created. The
+// Table keeps whatever the earlier messages wrote to it, and is left open for
+// the caller to clear or close. Messages produced afterward are applied in the
+// background, and the first one that can't be applied stops the View
+func MakeGlobal[Msg any, Key comparable, Value any](
+	t table.Table[Key, Value],
+	top topic.Topic[Msg],
+	u table.Updater[Msg, Key, Value],
+) (table.View[Key, Value], error) {
+	g := &global[Msg, Key, Value]{
+		Table:    t,
+		updater:  u,
+		consumer: top.NewConsumer(),
+		follower: makeFollower(),
+	}
+	if err := checkRetained(top); err != nil {
+		g.consumer.Close()
+		return nil, err
+	}
+	for i := top.Length(); i > 0; i-- {
+		msg, ok := <-g.consumer.Receive()
+		if !ok {
+			g.consumer.Close()
+			return nil, errors.New(table.ErrSourceClosed)
+		}
+		if err := u.Update(msg); err != nil {
+			g.consumer.Close()
+			return nil, err
+		}
+	}
+	go g.follow()
+	return g, nil
+}
+
+// checkRetained makes sure that the Topic still holds its first message, if
+// it can tell. Otherwise, consuming as many messages as its Length would wait
+// for messages that haven't been produced yet
+func checkRetained[Msg any](top topic.Topic[Msg]) error {
+	r, ok := top.(offsetReader[Msg])
+	if !ok {
+		return nil
+	}
+	if _, o, _ := r.Get(0); o != 0 {
+		return errors.New(table.ErrTopicDiscarded)
+	}
+	return nil
+}
+
+// Close stops the View from following its Topic, and then closes its Table
+func (g *global[_, _, _]) Close() error {
+	g.halt()
+	return g.Table.Close()
+}
+
+// follow applies each message produced to the Topic, until the View is closed
+// or a message can't be applied
+func (g *global[_, _, _]) follow() {
+	defer func() {
+		g.consumer.Close()
+		close(g.done)
+	}()
+	for {
+		select {
+		case <-g.closed:
+			return
+		case msg, ok := <-g.consumer.Receive():
+			if !ok {
+				g.stop(errors.New(table.ErrSourceClosed))
+				return
+			}
+			if err := g.updater.Update(msg); err != nil {
+				g.stop(err)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package table_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/caravan/essentials"
+	"github.com/caravan/essentials/topic/config"
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/column"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/streaming/internal/table"
+)
+
+func makeGlobalUpdater(
+	tbl table.Table[string, any],
+) (table.Updater[*tableRow, string, any], error) {
+	return internal.MakeTombstoneUpdater(tbl,
+		func(r *tableRow) string {
+			return r.key
+		},
+		func(r *tableRow) bool {
+			return r.name == ""
+		},
+		column.Make("name", func(r *tableRow) any {
+			return r.name
+		}),
+	)
+}
+
+func TestGlobal(t *testing.T) {
+	as := assert.New(t)
+
+	top := essentials.NewTopic[*tableRow]()
+	p := top.NewProducer()
+	defer p.Close()
+	for i, n := range []string{"bill", "jane", "june"} {
+		p.Send() <- &tableRow{key: string(rune('a' + i)), name: n}
+	}
+	p.Send() <- &tableRow{key: "b"}
+	as.Eventually(func() bool {
+		return top.Length() == 4
+	}, time.Second, time.Millisecond)
+
+	tbl, _ := internal.Make[string, any]("name")
+	u, err := makeGlobalUpdater(tbl)
+	as.Nil(err)
+	g, err := internal.MakeGlobal(tbl, top, u)
+	as.Nil(err)
+	defer g.Close()
+
+	// Every message already in the Topic is applied before it returns
+	as.Equal(2, g.Len())
+	get, _ := g.Getter("name")
+	res, err := get("c")
+	as.Nil(err)
+	as.Equal([]any{"june"}, res)
+
+	p.Send() <- &tableRow{key: "d", name: "bob"}
+	as.Eventually(func() bool {
+		res, err := get("d")
+		return err == nil && res[0] == "bob"
+	}, time.Second, time.Millisecond)
+
+	g.Close()
+	<-g.IsClosed()
+	p.Send() <- &tableRow{key: "e", name: "sam"}
+	time.Sleep(10 * time.Millisecond)
+	as.Equal(3, g.Len())
+}
+
+func TestGlobalError(t *testing.T) {
+	as := assert.New(t)
+
+	top := essentials.NewTopic[*tableRow]()
+	p := top.NewProducer()
+	defer p.Close()
+	p.Send() <- &tableRow{key: "a", name: "bill"}
+	as.Eventually(func() bool {
+		return top.Length() == 1
+	}, time.Second, time.Millisecond)
+
+	p.Send() <- &tableRow{key: "b", name: "fail"}
+	p.Send() <- &tableRow{key: "c", name: "jane"}
+	as.Eventually(func() bool {
+		return top.Length() == 3
+	}, time.Second, time.Millisecond)
+
+	tbl, _ := internal.Make[string, any]("name")
+	u, _ := makeGlobalUpdater(tbl)
+	_, err := internal.MakeGlobal[*tableRow, string, any](tbl, top,
+		failingUpdater{u},
+	)
+	as.EqualError(err, "update failed")
+
+	// The rows written before the failure are left in the Table
+	as.Equal(1, tbl.Len())
+	as.Nil(tbl.Close())
+}
+
+func TestGlobalFollowError(t *testing.T) {
+	as := assert.New(t)
+
+	top := essentials.NewTopic[*tableRow]()
+	p := top.NewProducer()
+	defer p.Close()
+
+	tbl, _ := internal.Make[string, any]("name")
+	u, _ := makeGlobalUpdater(tbl)
+	g, err := internal.MakeGlobal[*tableRow, string, any](tbl, top,
+		failingUpdater{u},
+	)
+	as.Nil(err)
+	defer g.Close()
+
+	p.Send() <- &tableRow{key: "a", name: "bill"}
+	p.Send() <- &tableRow{key: "b", name: "fail"}
+	<-g.IsClosed()
+	as.EqualError(g.Err(), "update failed")
+	as.Equal(1, g.Len())
+
+	p.Send() <- &tableRow{key: "c", name: "jane"}
+	time.Sleep(10 * time.Millisecond)
+	as.Equal(1, g.Len())
+}
+
+func TestGlobalDiscarded(t *testing.T) {
+	as := assert.New(t)
+
+	top := essentials.NewTopic[*tableRow](config.Counted(2))
+	p := top.NewProducer()
+	defer p.Close()
+	for i := 0; i < 100; i++ {
+		p.Send() <- &tableRow{key: fmt.Sprint(i), name: "bill"}
+	}
+
+	// Once the Topic has discarded its first messages, waiting for as many
+	// messages as its Length would block until more are produced
+	as.Eventually(func() bool {
+		tbl, _ := internal.Make[string, any]("name")
+		u, _ := makeGlobalUpdater(tbl)
+		g, err := internal.MakeGlobal(tbl, top, u)
+		if err == nil {
+			_ = g.Close()
+			return false
+		}
+		as.EqualError(err, table.ErrTopicDiscarded)
+		return true
+	}, time.Second, 10*time.Millisecond)
+}
+
+// failingUpdater fails to apply any row whose name is "fail"
+type failingUpdater struct {
+	table.Updater[*tableRow, string, any]
+}
+
+func (u failingUpdater) Update(r *tableRow) error {
+	if r.name == "fail" {
+		return errors.New("update failed")
+	}
+	return u.Updater.Update(r)
+}
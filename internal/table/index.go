@@ -0,0 +1,195 @@
+package table
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/config"
+)
+
+// index is a secondary index that maps the Values of one or more columns to
+// the Keys of the rows that contain them. A multi-column index combines its
+// Values into an array of type tuple, so that they can be used as a map key
+type index[Key comparable, Value any] struct {
+	names   []table.ColumnName
+	columns []int
+	entries map[any]map[Key]struct{}
+	tuple   reflect.Type
+}
+
+var anyType = reflect.TypeOf((*any)(nil)).Elem()
+
+func (t *Table[Key, Value]) makeIndexes(cfg *config.Config) error {
+	for _, names := range cfg.Indexes {
+		if err := checkColumnDuplicates(names); err != nil {
+			return err
+		}
+		columns, err := t.columnIndexes(names)
+		if err != nil {
+			return err
+		}
+		ix := &index[Key, Value]{
+			names:   names,
+			columns: columns,
+			entries: map[any]map[Key]struct{}{},
+		}
+		if len(columns) > 1 {
+			ix.tuple = reflect.ArrayOf(len(columns), anyType)
+		}
+		t.secondary = append(t.secondary, ix)
+	}
+	return nil
+}
+
+func (t *Table[Key, Value]) Finder(
+	idx []table.ColumnName, c ...table.ColumnName,
+) (table.Finder[Key, Value], error) {
+	ix, err := t.findIndex(idx)
+	if err != nil {
+		return nil, err
+	}
+	sel, err := t.selectColumns(c)
+	if err != nil {
+		return nil, err
+	}
+	return func(v ...Value) ([]Key, [][]Value, error) {
+		// The index's columns and entries change under the write lock, so
+		// the entry must be resolved while holding the read lock
+		t.RLock()
+		defer t.RUnlock()
+
+		if len(v) != len(ix.columns) {
+			return nil, nil, fmt.Errorf(
+				table.ErrValueCountRequired, len(ix.columns), len(v),
+			)
+		}
+		e := ix.entryOf(v)
+		if err := ix.check(e); err != nil {
+			return nil, nil, err
+		}
+
+		indexes, err := t.positions(sel)
+		if err != nil {
+			return nil, nil, err
+		}
+		now := time.Now()
+		keys := ix.entries[e]
+		resKeys := make([]Key, 0, len(keys))
+		resValues := make([][]Value, 0, len(keys))
+		for k := range keys {
+			if t.isExpired(k, now) {
+				continue
+			}
+			row, _, err := t.rows.Get(k)
+			if err != nil {
+				return nil, nil, err
+			}
+			s := selectRow(k, row, indexes)
+			resKeys = append(resKeys, s.key)
+			resValues = append(resValues, s.values)
+		}
+		t.countReads(indexes, len(resKeys))
+		return resKeys, resValues, nil
+	}, nil
+}
+
+func (t *Table[Key, Value]) findIndex(
+	names []table.ColumnName,
+) (*index[Key, Value], error) {
+	for _, ix := range t.secondary {
+		if sameColumns(ix.names, names) {
+			return ix, nil
+		}
+	}
+	return nil, fmt.Errorf(table.ErrIndexNotFound, names)
+}
+
+// indexEntries returns the entry of a row for each of the Table's indexes,
+// failing if any of them can't be indexed
+func (t *Table[_, Value]) indexEntries(row []Value) ([]any, error) {
+	if len(t.secondary) == 0 {
+		return nil, nil
+	}
+	res := make([]any, len(t.secondary))
+	for i, ix := range t.secondary {
+		e := ix.rowEntry(row)
+		if err := ix.check(e); err != nil {
+			return nil, err
+		}
+		res[i] = e
+	}
+	return res, nil
+}
+
+func (t *Table[Key, Value]) unindex(k Key, row []Value) {
+	for _, ix := range t.secondary {
+		ix.remove(ix.rowEntry(row), k)
+	}
+}
+
+func (ix *index[_, Value]) rowEntry(row []Value) any {
+	v := make([]Value, len(ix.columns))
+	for i, c := range ix.columns {
+		v[i] = row[c]
+	}
+	return ix.entryOf(v)
+}
+
+// entryOf returns the entry for Values given in the order of the index's
+// columns
+func (ix *index[_, Value]) entryOf(v []Value) any {
+	if ix.tuple == nil {
+		return v[0]
+	}
+	res := reflect.New(ix.tuple).Elem()
+	for i := range ix.columns {
+		if e := any(v[i]); e != nil {
+			res.Index(i).Set(reflect.ValueOf(e))
+		}
+	}
+	return res.Interface()
+}
+
+func (ix *index[Key, _]) add(e any, k Key) {
+	keys, ok := ix.entries[e]
+	if !ok {
+		keys = map[Key]struct{}{}
+		ix.entries[e] = keys
+	}
+	keys[k] = struct{}{}
+}
+
+func (ix *index[Key, _]) remove(e any, k Key) {
+	if keys, ok := ix.entries[e]; ok {
+		delete(keys, k)
+		if len(keys) == 0 {
+			delete(ix.entries, e)
+		}
+	}
+}
+
+// check fails if the entry can't be used as a map key, which is the case when
+// it holds a slice, a map, or a function
+func (ix *index[_, _]) check(e any) (err error) {
+	defer func() {
+		if recover() != nil {
+			err = fmt.Errorf(table.ErrIndexValue, e)
+		}
+	}()
+	_ = ix.entries[e]
+	return nil
+}
+
+func sameColumns(l, r []table.ColumnName) bool {
+	if len(l) != len(r) {
+		return false
+	}
+	for i, n := range l {
+		if r[i] != n {
+			return false
+		}
+	}
+	return true
+}
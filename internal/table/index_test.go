@@ -0,0 +1,149 @@
+package table_test
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/config"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/streaming/internal/table"
+)
+
+func TestIndex(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := internal.MakeWith[int, any](
+		[]table.ColumnName{"name", "city", "age"},
+		config.Index("city"),
+		config.Index("city", "age"),
+	)
+	as.Nil(err)
+	setter, _ := tbl.Setter("name", "city", "age")
+	as.Nil(setter(1, "bob", "paris", 42))
+	as.Nil(setter(2, "june", "paris", 36))
+	as.Nil(setter(3, "carol", "rome", 42))
+
+	byCity, err := tbl.Finder([]table.ColumnName{"city"}, "name")
+	as.Nil(err)
+	keys, rows, err := byCity("paris")
+	as.Nil(err)
+	as.ElementsMatch([]int{1, 2}, keys)
+	as.ElementsMatch([][]any{{"bob"}, {"june"}}, rows)
+
+	byCityAge, err := tbl.Finder(
+		[]table.ColumnName{"city", "age"}, "name", "age",
+	)
+	as.Nil(err)
+	keys, rows, err = byCityAge("rome", 42)
+	as.Nil(err)
+	as.Equal([]int{3}, keys)
+	as.Equal([][]any{{"carol", 42}}, rows)
+
+	// Updates move a row between entries
+	citySetter, _ := tbl.Setter("city")
+	as.Nil(citySetter(2, "rome"))
+	keys, _, _ = byCity("paris")
+	as.Equal([]int{1}, keys)
+	keys, _, _ = byCity("rome")
+	sort.Ints(keys)
+	as.Equal([]int{2, 3}, keys)
+
+	// Deletes remove it
+	as.Nil(tbl.Deleter()(1))
+	keys, rows, err = byCity("paris")
+	as.Nil(err)
+	as.Empty(keys)
+	as.Empty(rows)
+	keys, _, _ = byCityAge("paris", 42)
+	as.Empty(keys)
+}
+
+func TestBadIndex(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := internal.MakeWith[int, any](
+		[]table.ColumnName{"name"}, config.Index("missing"),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, fmt.Sprintf(table.ErrColumnNotFound, "missing"))
+
+	tbl, err = internal.MakeWith[int, any](
+		[]table.ColumnName{"name"}, config.Index("name", "name"),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, fmt.Sprintf(table.ErrDuplicateColumnName, "name"))
+
+	tbl, _ = internal.MakeWith[int, any](
+		[]table.ColumnName{"name", "tags"}, config.Index("tags"),
+	)
+	f, err := tbl.Finder([]table.ColumnName{"name"})
+	as.Nil(f)
+	as.EqualError(err, fmt.Sprintf(table.ErrIndexNotFound, []string{"name"}))
+
+	f, err = tbl.Finder([]table.ColumnName{"tags"}, "missing")
+	as.Nil(f)
+	as.EqualError(err, fmt.Sprintf(table.ErrColumnNotFound, "missing"))
+
+	// Unhashable Values are refused without changing the Table
+	setter, _ := tbl.Setter("name", "tags")
+	as.Nil(setter(1, "bob", "admin"))
+	err = setter(1, "bob", []string{"admin"})
+	as.EqualError(err, fmt.Sprintf(table.ErrIndexValue, []string{"admin"}))
+	getter, _ := tbl.Getter("tags")
+	res, _ := getter(1)
+	as.Equal([]any{"admin"}, res)
+
+	f, _ = tbl.Finder([]table.ColumnName{"tags"}, "name")
+	_, _, err = f([]string{"admin"})
+	as.EqualError(err, fmt.Sprintf(table.ErrIndexValue, []string{"admin"}))
+	_, _, err = f("admin", "user")
+	as.EqualError(err, fmt.Sprintf(table.ErrValueCountRequired, 1, 2))
+}
+
+func TestIndexExpired(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.MakeWith[int, any](
+		[]table.ColumnName{"name"},
+		config.Index("name"),
+		config.TTL(20*time.Millisecond),
+	)
+	setter, _ := tbl.Setter("name")
+	as.Nil(setter(1, "bob"))
+	f, _ := tbl.Finder([]table.ColumnName{"name"}, "name")
+	keys, _, _ := f("bob")
+	as.Equal([]int{1}, keys)
+
+	time.Sleep(30 * time.Millisecond)
+	keys, _, _ = f("bob")
+	as.Empty(keys)
+}
+
+func TestIndexRestore(t *testing.T) {
+	as := assert.New(t)
+
+	path := t.TempDir() + "/changelog"
+	tbl, _ := internal.MakeWith[int, any](
+		[]table.ColumnName{"name"}, config.ChangelogFile(path),
+	)
+	setter, _ := tbl.Setter("name")
+	as.Nil(setter(1, "bob"))
+	as.Nil(setter(2, "bob"))
+	as.Nil(setter(2, "june"))
+
+	tbl, err := internal.MakeWith[int, any](
+		[]table.ColumnName{"name"},
+		config.ChangelogFile(path),
+		config.Index("name"),
+	)
+	as.Nil(err)
+	f, _ := tbl.Finder([]table.ColumnName{"name"})
+	keys, _, _ := f("bob")
+	as.Equal([]int{1}, keys)
+	keys, _, _ = f("june")
+	as.Equal([]int{2}, keys)
+}
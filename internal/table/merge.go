@@ -0,0 +1,51 @@
+package table
+
+import (
+	"fmt"
+
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/config"
+)
+
+// makeMerges returns the Reducer declared for each column, or nil if no
+// Reducers have been declared
+func makeMerges[Value any](
+	cfg *config.Config, indexes map[table.ColumnName]int,
+) ([]table.Reducer[Value], error) {
+	if len(cfg.Merges) == 0 {
+		return nil, nil
+	}
+	res := make([]table.Reducer[Value], len(indexes))
+	for n, m := range cfg.Merges {
+		i, ok := indexes[n]
+		if !ok {
+			return nil, fmt.Errorf(table.ErrColumnNotFound, n)
+		}
+		r, ok := m.(table.Reducer[Value])
+		if !ok {
+			return nil, fmt.Errorf(config.ErrMergeType, n, m)
+		}
+		res[i] = r
+	}
+	return res, nil
+}
+
+// assign writes the Values to the columns of a row at the provided positions.
+// A Value written to a column that's already set in an existing row is merged
+// with the Value it holds, if the column has a Reducer
+func (t *Table[_, Value]) assign(
+	row []Value, exists bool, u unset, indexes []int, v []Value,
+) error {
+	for in, out := range indexes {
+		if t.merges == nil || t.merges[out] == nil || !exists || u.has(out) {
+			row[out] = v[in]
+			continue
+		}
+		res, err := t.merges[out](row[out], v[in])
+		if err != nil {
+			return err
+		}
+		row[out] = res
+	}
+	return nil
+}
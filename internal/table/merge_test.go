@@ -0,0 +1,155 @@
+package table_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/column"
+	"github.com/caravan/streaming/table/config"
+	"github.com/caravan/streaming/table/merge"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/streaming/internal/table"
+)
+
+type pageView struct {
+	page     string
+	user     string
+	duration int
+}
+
+func TestMerge(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := internal.MakeWith[string, any](
+		[]table.ColumnName{"views", "longest", "users", "last"},
+		config.Merge("views", merge.Sum[any]()),
+		config.Merge("longest", merge.Max[any]()),
+		config.Merge("users", merge.Append[any]()),
+		config.Merge("last", merge.LastNonNull[any]()),
+	)
+	as.Nil(err)
+
+	updater, err := internal.MakeUpdater[*pageView, string, any](tbl,
+		func(v *pageView) string {
+			return v.page
+		},
+		column.Make("views", func(*pageView) any {
+			return 1
+		}),
+		column.Make("longest", func(v *pageView) any {
+			return v.duration
+		}),
+		column.Make("users", func(v *pageView) any {
+			return []string{v.user}
+		}),
+		column.Make("last", func(v *pageView) any {
+			if v.user == "" {
+				return nil
+			}
+			return v.user
+		}),
+	)
+	as.Nil(err)
+
+	as.Nil(updater.Update(&pageView{page: "/", user: "bob", duration: 10}))
+	as.Nil(updater.Update(&pageView{page: "/", user: "june", duration: 30}))
+	as.Nil(updater.Update(&pageView{page: "/", duration: 20}))
+
+	getter, _ := tbl.Getter("views", "longest", "users", "last")
+	res, err := getter("/")
+	as.Nil(err)
+	as.Equal([]any{3, 30, []string{"bob", "june", ""}, "june"}, res)
+}
+
+func TestMergeUnset(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.MakeWith[string, any](
+		[]table.ColumnName{"name", "total"},
+		config.Merge("total", merge.Sum[any]()),
+	)
+	setName, _ := tbl.Setter("name")
+	setTotal, _ := tbl.Setter("total")
+	getter, _ := tbl.Getter("total")
+
+	// A column that hasn't been set takes the first Value written to it
+	as.Nil(setName("1", "bob"))
+	as.Nil(setTotal("1", 5))
+	as.Nil(setTotal("1", 5))
+	res, _ := getter("1")
+	as.Equal([]any{10}, res)
+
+	// A failed merge leaves the row untouched
+	err := setTotal("1", "five")
+	as.EqualError(err, fmt.Sprintf(merge.ErrIncompatibleValues, 10, "five"))
+	res, _ = getter("1")
+	as.Equal([]any{10}, res)
+
+	tx := tbl.Begin()
+	txSet, _ := tx.Setter("total")
+	as.Nil(txSet("1", 1))
+	as.Nil(txSet("1", 1))
+	as.Nil(txSet("2", 1))
+	as.Nil(txSet("2", 1))
+	as.Nil(tx.Commit())
+	res, _ = getter("1")
+	as.Equal([]any{12}, res)
+	res, _ = getter("2")
+	as.Equal([]any{2}, res)
+}
+
+func TestConcurrentMerge(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.MakeWith[string, int](
+		[]table.ColumnName{"count"},
+		config.Merge("count", merge.Sum[int]()),
+	)
+	setter, _ := tbl.Setter("count")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = setter("counter", 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	getter, _ := tbl.Getter("count")
+	res, _ := getter("counter")
+	as.Equal([]int{1000}, res)
+}
+
+func TestMergeErrors(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := internal.MakeWith[string, any](
+		[]table.ColumnName{"count"},
+		config.Merge("missing", merge.Sum[any]()),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, fmt.Sprintf(table.ErrColumnNotFound, "missing"))
+
+	sum := merge.Sum[int]()
+	tbl, err = internal.MakeWith[string, any](
+		[]table.ColumnName{"count"},
+		config.Merge("count", sum),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, fmt.Sprintf(config.ErrMergeType, "count", sum))
+
+	tbl, err = internal.MakeWith[string, any](
+		[]table.ColumnName{"count"},
+		config.Merge("count", merge.Sum[any]()),
+		config.Merge("count", merge.Max[any]()),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, fmt.Sprintf(config.ErrMergeAlreadySet, "count"))
+}
@@ -0,0 +1,166 @@
+package table
+
+import (
+	"errors"
+	"time"
+
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/config"
+)
+
+type (
+	// Ordered is the internal implementation of a table.OrderedTable
+	Ordered[Key table.Ordered, Value any] struct {
+		*Table[Key, Value]
+		tree *treeStore[Key, Value]
+	}
+
+	// ranger is the internal implementation of a table.Ranger
+	ranger[Key table.Ordered, Value any] struct {
+		*Ordered[Key, Value]
+		*selection
+	}
+)
+
+// MakeOrdered instantiates a new internal Ordered Table instance, applying
+// the provided Options to its configuration
+func MakeOrdered[Key table.Ordered, Value any](
+	c []table.ColumnName, o ...config.Option,
+) (table.OrderedTable[Key, Value], error) {
+	cfg, err := makeConfig(c, o)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Shards > 1 {
+		return nil, errors.New(config.ErrShardedOrdered)
+	}
+	if cfg.Store != nil {
+		return nil, errors.New(config.ErrOrderedStore)
+	}
+	if isBounded(cfg) {
+		return nil, errors.New(config.ErrOrderedBounds)
+	}
+	tree := &treeStore[Key, Value]{}
+	t, err := makeTable[Key, Value](c, tree, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Ordered[Key, Value]{
+		Table: t,
+		tree:  tree,
+	}, nil
+}
+
+func (t *Ordered[Key, Value]) Ranger(
+	c ...table.ColumnName,
+) (table.Ranger[Key, Value], error) {
+	sel, err := t.selectColumns(c)
+	if err != nil {
+		return nil, err
+	}
+	return &ranger[Key, Value]{
+		Ordered:   t,
+		selection: sel,
+	}, nil
+}
+
+func (r *ranger[Key, Value]) Range(from, to Key) table.Scanner[Key, Value] {
+	return r.scanner(boundAt(from), boundAt(to))
+}
+
+func (r *ranger[Key, Value]) From(from Key) table.Scanner[Key, Value] {
+	return r.scanner(boundAt(from), bound[Key]{})
+}
+
+func (r *ranger[Key, Value]) Until(to Key) table.Scanner[Key, Value] {
+	return r.scanner(bound[Key]{}, boundAt(to))
+}
+
+func (r *ranger[Key, Value]) Floor(k Key) (Key, []Value, bool, error) {
+	return r.first(func(fn func(Key, []Value) bool) {
+		r.tree.descend(boundAt(k), fn)
+	})
+}
+
+func (r *ranger[Key, Value]) Ceiling(k Key) (Key, []Value, bool, error) {
+	return r.first(func(fn func(Key, []Value) bool) {
+		r.tree.ascend(boundAt(k), bound[Key]{}, fn)
+	})
+}
+
+func (r *ranger[Key, Value]) Min() (Key, []Value, bool, error) {
+	return r.first(func(fn func(Key, []Value) bool) {
+		r.tree.ascend(bound[Key]{}, bound[Key]{}, fn)
+	})
+}
+
+func (r *ranger[Key, Value]) Max() (Key, []Value, bool, error) {
+	return r.first(func(fn func(Key, []Value) bool) {
+		r.tree.descend(bound[Key]{}, fn)
+	})
+}
+
+func (r *ranger[Key, Value]) scanner(
+	from, to bound[Key],
+) table.Scanner[Key, Value] {
+	return func(v table.Visitor[Key, Value]) error {
+		rows, err := r.selectRange(from, to)
+		if err != nil {
+			return err
+		}
+		visit(rows, v)
+		return nil
+	}
+}
+
+// selectRange copies the selected column Values of the rows in the range
+// while holding the read lock, so that they can be visited without it
+func (r *ranger[Key, Value]) selectRange(
+	from, to bound[Key],
+) ([]*selected[Key, Value], error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	indexes, err := r.positions(r.selection)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var res []*selected[Key, Value]
+	r.tree.ascend(from, to, func(k Key, row []Value) bool {
+		if !r.isExpired(k, now) {
+			res = append(res, selectRow(k, row, indexes))
+		}
+		return true
+	})
+	r.countReads(indexes, len(res))
+	return res, nil
+}
+
+// first returns the first row visited by the traversal that hasn't expired
+func (r *ranger[Key, Value]) first(
+	traverse func(func(Key, []Value) bool),
+) (Key, []Value, bool, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	var zero Key
+	indexes, err := r.positions(r.selection)
+	if err != nil {
+		return zero, nil, false, err
+	}
+	now := time.Now()
+	var res *selected[Key, Value]
+	traverse(func(k Key, row []Value) bool {
+		if r.isExpired(k, now) {
+			return true
+		}
+		res = selectRow(k, row, indexes)
+		return false
+	})
+	if res == nil {
+		return zero, nil, false, nil
+	}
+	r.countReads(indexes, 1)
+	return res.key, res.values, true, nil
+}
@@ -0,0 +1,235 @@
+package table_test
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/config"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/streaming/internal/table"
+)
+
+func makeReadings(t *testing.T) table.OrderedTable[int, any] {
+	tbl, err := internal.MakeOrdered[int, any](
+		[]table.ColumnName{"sensor", "reading"},
+	)
+	assert.NotNil(t, tbl)
+	assert.Nil(t, err)
+	setter, _ := tbl.Setter("sensor", "reading")
+	for _, ts := range []int{50, 10, 40, 20, 30} {
+		_ = setter(ts, "temp", ts*2)
+	}
+	return tbl
+}
+
+func TestOrderedTable(t *testing.T) {
+	as := assert.New(t)
+
+	tbl := makeReadings(t)
+	as.Equal(5, tbl.Len())
+
+	scan, _ := tbl.Scanner("reading")
+	keys, rows := collect(t, scan)
+	as.Equal([]int{10, 20, 30, 40, 50}, keys)
+	as.Equal([][]any{{20}, {40}, {60}, {80}, {100}}, rows)
+
+	getter, _ := tbl.Getter("reading")
+	res, _ := getter(30)
+	as.Equal([]any{60}, res)
+
+	as.Nil(tbl.Deleter()(30))
+	_, err := getter(30)
+	as.EqualError(err, fmt.Sprintf(table.ErrKeyNotFound, 30))
+	keys, _ = collect(t, scan)
+	as.Equal([]int{10, 20, 40, 50}, keys)
+}
+
+func TestRanger(t *testing.T) {
+	as := assert.New(t)
+
+	tbl := makeReadings(t)
+	r, err := tbl.Ranger("reading")
+	as.NotNil(r)
+	as.Nil(err)
+
+	keys, rows := collect(t, r.Range(20, 40))
+	as.Equal([]int{20, 30}, keys)
+	as.Equal([][]any{{40}, {60}}, rows)
+
+	keys, _ = collect(t, r.From(35))
+	as.Equal([]int{40, 50}, keys)
+
+	keys, _ = collect(t, r.Until(30))
+	as.Equal([]int{10, 20}, keys)
+
+	keys, _ = collect(t, r.Range(60, 70))
+	as.Nil(keys)
+
+	k, row, ok, err := r.Floor(35)
+	as.Nil(err)
+	as.True(ok)
+	as.Equal(30, k)
+	as.Equal([]any{60}, row)
+
+	k, _, ok, err = r.Floor(30)
+	as.Nil(err)
+	as.True(ok)
+	as.Equal(30, k)
+
+	_, _, ok, err = r.Floor(5)
+	as.Nil(err)
+	as.False(ok)
+
+	k, _, ok, err = r.Ceiling(35)
+	as.Nil(err)
+	as.True(ok)
+	as.Equal(40, k)
+
+	_, _, ok, err = r.Ceiling(55)
+	as.Nil(err)
+	as.False(ok)
+
+	k, row, ok, err = r.Min()
+	as.Nil(err)
+	as.True(ok)
+	as.Equal(10, k)
+	as.Equal([]any{20}, row)
+
+	k, row, ok, err = r.Max()
+	as.Nil(err)
+	as.True(ok)
+	as.Equal(50, k)
+	as.Equal([]any{100}, row)
+
+	r, err = tbl.Ranger("missing")
+	as.Nil(r)
+	as.EqualError(err, fmt.Sprintf(table.ErrColumnNotFound, "missing"))
+}
+
+func TestEmptyRanger(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.MakeOrdered[int, any]([]table.ColumnName{"value"})
+	r, _ := tbl.Ranger("value")
+	_, _, ok, err := r.Min()
+	as.Nil(err)
+	as.False(ok)
+	_, _, ok, err = r.Max()
+	as.Nil(err)
+	as.False(ok)
+	_, _, ok, err = r.Floor(10)
+	as.Nil(err)
+	as.False(ok)
+}
+
+func TestPrefix(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.MakeOrdered[string, any]([]table.ColumnName{"value"})
+	setter, _ := tbl.Setter("value")
+	for _, k := range []string{
+		"apple", "app", "apricot", "banana", "ap", "b", "\xff", "\xff\xff",
+	} {
+		as.Nil(setter(k, len(k)))
+	}
+
+	r, _ := tbl.Ranger("value")
+	keys, _ := collect(t, table.Prefix(r, "ap"))
+	as.Equal([]string{"ap", "app", "apple", "apricot"}, keys)
+
+	keys, _ = collect(t, table.Prefix(r, "b"))
+	as.Equal([]string{"b", "banana"}, keys)
+
+	keys, _ = collect(t, table.Prefix(r, "\xff"))
+	as.Equal([]string{"\xff", "\xff\xff"}, keys)
+
+	keys, _ = collect(t, table.Prefix(r, ""))
+	as.Len(keys, 8)
+}
+
+func TestRangerExpired(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.MakeOrdered[int, any](
+		[]table.ColumnName{"value"},
+		config.TTL(10*time.Millisecond),
+		config.SweepInterval(time.Hour),
+	)
+	setter, _ := tbl.Setter("value")
+	as.Nil(setter(10, "first"))
+	as.Nil(setter(30, "third"))
+	time.Sleep(20 * time.Millisecond)
+	as.Nil(setter(20, "second"))
+
+	r, _ := tbl.Ranger("value")
+	k, _, ok, err := r.Floor(30)
+	as.Nil(err)
+	as.True(ok)
+	as.Equal(20, k)
+	_, _, ok, err = r.Floor(15)
+	as.Nil(err)
+	as.False(ok)
+	k, _, _, err = r.Min()
+	as.Nil(err)
+	as.Equal(20, k)
+	k, _, _, err = r.Max()
+	as.Nil(err)
+	as.Equal(20, k)
+	keys, _ := collect(t, r.From(0))
+	as.Equal([]int{20}, keys)
+}
+
+func TestOrderedRandomized(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.MakeOrdered[int, any]([]table.ColumnName{"value"})
+	setter, _ := tbl.Setter("value")
+	deleter := tbl.Deleter()
+	r, _ := tbl.Ranger("value")
+
+	rnd := rand.New(rand.NewSource(42))
+	expected := map[int]bool{}
+	for i := 0; i < 5000; i++ {
+		k := rnd.Intn(1000)
+		if rnd.Intn(3) == 0 {
+			as.Nil(deleter(k))
+			delete(expected, k)
+		} else {
+			as.Nil(setter(k, k))
+			expected[k] = true
+		}
+	}
+
+	var sorted []int
+	for k := range expected {
+		sorted = append(sorted, k)
+	}
+	sort.Ints(sorted)
+
+	as.Equal(len(sorted), tbl.Len())
+	keys, _ := collect(t, r.From(0))
+	as.Equal(sorted, keys)
+
+	lo, hi := sorted[0], sorted[len(sorted)-1]
+	k, _, _, err := r.Min()
+	as.Nil(err)
+	as.Equal(lo, k)
+	k, _, _, err = r.Max()
+	as.Nil(err)
+	as.Equal(hi, k)
+
+	for probe := 0; probe < 1000; probe++ {
+		i := sort.SearchInts(sorted, probe)
+		k, _, ok, err := r.Ceiling(probe)
+		as.Nil(err)
+		as.Equal(i < len(sorted), ok)
+		if ok {
+			as.Equal(sorted[i], k)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package table
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/config"
+)
+
+// unset is the set of column positions that haven't been set in a row. Only
+// rows with unset columns have one, so fully written rows cost nothing
+type unset []uint64
+
+func (t *Table[Key, Value]) PresenceGetter(
+	c ...table.ColumnName,
+) (table.PresenceGetter[Key, Value], error) {
+	sel, err := t.selectColumns(c)
+	if err != nil {
+		return nil, err
+	}
+	return func(k Key) ([]Value, []bool, error) {
+		t.RLock()
+		defer t.RUnlock()
+
+		indexes, err := t.positions(sel)
+		if err != nil {
+			return nil, nil, err
+		}
+		e, ok, err := t.rows.Get(k)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ok && !t.isExpired(k, time.Now()) {
+			t.hits.Add(1)
+			t.countReads(indexes, 1)
+			u := t.unset[k]
+			res := make([]Value, len(indexes))
+			set := make([]bool, len(indexes))
+			for out, in := range indexes {
+				res[out] = e[in]
+				set[out] = !u.has(in)
+			}
+			return res, set, nil
+		}
+		t.misses.Add(1)
+		return nil, nil, fmt.Errorf(table.ErrKeyNotFound, k)
+	}, nil
+}
+
+// makeDefaults returns the row that new rows start from, holding each
+// column's declared default, or nil if no defaults have been declared
+func makeDefaults[Value any](
+	cfg *config.Config, indexes map[table.ColumnName]int,
+) ([]Value, error) {
+	if len(cfg.ColumnDefaults) == 0 {
+		return nil, nil
+	}
+	res := make([]Value, len(indexes))
+	for n, d := range cfg.ColumnDefaults {
+		i, ok := indexes[n]
+		if !ok {
+			return nil, fmt.Errorf(table.ErrColumnNotFound, n)
+		}
+		if d == nil {
+			continue
+		}
+		v, ok := d.(Value)
+		if !ok {
+			return nil, fmt.Errorf(config.ErrColumnDefaultType, n, d)
+		}
+		res[i] = v
+	}
+	return res, nil
+}
+
+// nextUnset returns the unset columns of a row once the columns at the
+// provided positions have been written to it. A row that doesn't exist yet
+// starts with every column unset
+func (t *Table[_, _]) nextUnset(prev unset, exists bool, indexes []int) unset {
+	var res unset
+	switch {
+	case exists && prev == nil:
+		return nil
+	case exists:
+		res = append(res, prev...)
+	default:
+		res = make(unset, (len(t.names)+63)/64)
+		for i := range t.names {
+			res.add(i)
+		}
+	}
+	for _, i := range indexes {
+		res.remove(i)
+	}
+	if res.isEmpty() {
+		return nil
+	}
+	return res
+}
+
+// storeUnset records the unset columns of a row. The caller must hold the
+// write lock
+func (t *Table[Key, _]) storeUnset(k Key, u unset) {
+	if u == nil {
+		delete(t.unset, k)
+		return
+	}
+	t.unset[k] = u
+}
+
+// unsetAt returns the set of the provided column positions, ignoring any
+// that are out of range for a row of the provided number of columns
+func unsetAt(positions []int, columns int) unset {
+	var res unset
+	for _, i := range positions {
+		if i < 0 || i >= columns {
+			continue
+		}
+		if res == nil {
+			res = make(unset, (columns+63)/64)
+		}
+		res.add(i)
+	}
+	return res
+}
+
+// positions returns the column positions in the set, which is how it's
+// persisted
+func (u unset) positions() []int {
+	var res []int
+	for i := 0; i < len(u)*64; i++ {
+		if u.has(i) {
+			res = append(res, i)
+		}
+	}
+	return res
+}
+
+func (u unset) has(i int) bool {
+	return i/64 < len(u) && u[i/64]&(1<<(i%64)) != 0
+}
+
+func (u unset) add(i int) {
+	u[i/64] |= 1 << (i % 64)
+}
+
+func (u unset) remove(i int) {
+	u[i/64] &^= 1 << (i % 64)
+}
+
+func (u unset) isEmpty() bool {
+	for _, w := range u {
+		if w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// with returns a copy of the set, sized for the provided number of columns,
+// that also contains the column at pos
+func (u unset) with(pos, columns int) unset {
+	res := make(unset, (columns+63)/64)
+	copy(res, u)
+	res.add(pos)
+	return res
+}
+
+// without returns the set as it's seen once the column at pos has been
+// removed, shifting the columns after it down by one. The set originally
+// covered the provided number of columns
+func (u unset) without(pos, columns int) unset {
+	res := make(unset, (columns+62)/64)
+	for i, j := 0, 0; i < columns; i++ {
+		if i == pos {
+			continue
+		}
+		if u.has(i) {
+			res.add(j)
+		}
+		j++
+	}
+	if res.isEmpty() {
+		return nil
+	}
+	return res
+}
@@ -0,0 +1,202 @@
+package table_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/codec"
+	"github.com/caravan/streaming/table/config"
+	"github.com/caravan/streaming/table/merge"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/streaming/internal/table"
+)
+
+func TestPresenceGetter(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := internal.Make[string, any]("name", "age", "city")
+	as.Nil(err)
+
+	setName, _ := tbl.Setter("name")
+	setAge, _ := tbl.Setter("age")
+	getter, err := tbl.PresenceGetter("name", "age", "city")
+	as.Nil(err)
+
+	as.Nil(setName("1", "bob"))
+	res, set, err := getter("1")
+	as.Nil(err)
+	as.Equal([]any{"bob", nil, nil}, res)
+	as.Equal([]bool{true, false, false}, set)
+
+	// A zero Value is still a set Value
+	as.Nil(setAge("1", 0))
+	res, set, _ = getter("1")
+	as.Equal([]any{"bob", 0, nil}, res)
+	as.Equal([]bool{true, true, false}, set)
+
+	// Deleting the row forgets which columns were set
+	as.Nil(tbl.Deleter()("1"))
+	as.Nil(setAge("1", 42))
+	_, set, _ = getter("1")
+	as.Equal([]bool{false, true, false}, set)
+
+	setAll, _ := tbl.Setter("name", "age", "city")
+	as.Nil(setAll("2", "june", 36, "paris"))
+	_, set, _ = getter("2")
+	as.Equal([]bool{true, true, true}, set)
+
+	_, _, err = getter("3")
+	as.EqualError(err, fmt.Sprintf(table.ErrKeyNotFound, "3"))
+	_, err = tbl.PresenceGetter("missing")
+	as.EqualError(err, fmt.Sprintf(table.ErrColumnNotFound, "missing"))
+}
+
+func TestRequiredGetter(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.Make[string, any]("name", "age")
+	setName, _ := tbl.Setter("name")
+	as.Nil(setName("1", "bob"))
+
+	getter, err := table.RequiredGetter(tbl, "name", "age")
+	as.Nil(err)
+	_, err = getter("1")
+	as.EqualError(err, fmt.Sprintf(table.ErrColumnNotSet, "1", "age"))
+
+	getter, _ = table.RequiredGetter(tbl, "name")
+	res, err := getter("1")
+	as.Nil(err)
+	as.Equal([]any{"bob"}, res)
+
+	_, err = table.RequiredGetter(tbl, "missing")
+	as.EqualError(err, fmt.Sprintf(table.ErrColumnNotFound, "missing"))
+}
+
+func TestColumnDefault(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := internal.MakeWith[string, any](
+		[]table.ColumnName{"name", "age", "city"},
+		config.ColumnDefault("city", "unknown"),
+		config.ColumnDefault[any]("age", nil),
+	)
+	as.Nil(err)
+
+	setName, _ := tbl.Setter("name")
+	as.Nil(setName("1", "bob"))
+
+	getter, _ := tbl.PresenceGetter("name", "age", "city")
+	res, set, _ := getter("1")
+	as.Equal([]any{"bob", nil, "unknown"}, res)
+	as.Equal([]bool{true, false, false}, set)
+
+	tx := tbl.Begin()
+	txSet, _ := tx.Setter("city")
+	as.Nil(txSet("2", "paris"))
+	as.Nil(txSet("1", "rome"))
+	as.Nil(tx.Commit())
+
+	res, set, _ = getter("2")
+	as.Equal([]any{nil, nil, "paris"}, res)
+	as.Equal([]bool{false, false, true}, set)
+	res, set, _ = getter("1")
+	as.Equal([]any{"bob", nil, "rome"}, res)
+	as.Equal([]bool{true, false, true}, set)
+}
+
+func TestColumnDefaultErrors(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := internal.MakeWith[string, string](
+		[]table.ColumnName{"name"},
+		config.ColumnDefault("missing", "x"),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, fmt.Sprintf(table.ErrColumnNotFound, "missing"))
+
+	tbl, err = internal.MakeWith[string, string](
+		[]table.ColumnName{"name"},
+		config.ColumnDefault("name", 42),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, fmt.Sprintf(config.ErrColumnDefaultType, "name", 42))
+
+	tbl, err = internal.MakeWith[string, string](
+		[]table.ColumnName{"name"},
+		config.ColumnDefault("name", "x"),
+		config.ColumnDefault("name", "y"),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, fmt.Sprintf(config.ErrColumnDefaultAlreadySet, "name"))
+}
+
+func TestShardedPresence(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.MakeWith[int, any](
+		[]table.ColumnName{"name", "age"},
+		config.Shards(4),
+	)
+	setName, _ := tbl.Setter("name")
+	getter, _ := tbl.PresenceGetter("age", "name")
+	for i := 0; i < 10; i++ {
+		as.Nil(setName(i, "bob"))
+		_, set, err := getter(i)
+		as.Nil(err)
+		as.Equal([]bool{false, true}, set)
+	}
+}
+
+func TestPersistedPresence(t *testing.T) {
+	for _, cc := range []codec.Codec{codec.Gob, codec.JSON} {
+		as := assert.New(t)
+
+		dir := t.TempDir()
+		cols := []table.ColumnName{"name", "visits"}
+		open := func() table.Table[string, any] {
+			tbl, err := internal.MakeWith[string, any](cols,
+				config.ChangelogFile(filepath.Join(dir, "changelog")),
+				config.Merge("visits", merge.Sum[any]()),
+				config.Codec(cc),
+			)
+			as.Nil(err)
+			return tbl
+		}
+
+		tbl := open()
+		setName, _ := tbl.Setter("name")
+		as.Nil(setName("1", "bob"))
+		tx := tbl.Begin()
+		txSet, _ := tx.Setter("name")
+		as.Nil(txSet("2", "june"))
+		as.Nil(tx.Commit())
+
+		var buf bytes.Buffer
+		as.Nil(tbl.Snapshot(&buf))
+		path := filepath.Join(dir, "snapshot")
+		as.Nil(os.WriteFile(path, buf.Bytes(), 0o644))
+		restored, err := internal.MakeWith[string, any](cols,
+			config.SnapshotFile(path),
+			config.Merge("visits", merge.Sum[any]()),
+			config.Codec(cc),
+		)
+		as.Nil(err)
+
+		// A never set column stays unset, so merging into it starts afresh
+		for _, tbl := range []table.Table[string, any]{open(), restored} {
+			getter, _ := tbl.PresenceGetter("name", "visits")
+			setVisits, _ := tbl.Setter("visits")
+			for _, k := range []string{"1", "2"} {
+				_, set, err := getter(k)
+				as.Nil(err)
+				as.Equal([]bool{true, false}, set)
+				as.Nil(setVisits(k, 1))
+			}
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package table
+
+import (
+	"time"
+
+	"github.com/caravan/streaming/table"
+)
+
+// selected holds the selected column Values of a row captured by a scan
+type selected[Key comparable, Value any] struct {
+	key    Key
+	values []Value
+	unset  unset
+}
+
+func (t *Table[Key, Value]) Scanner(
+	c ...table.ColumnName,
+) (table.Scanner[Key, Value], error) {
+	sel, err := t.selectColumns(c)
+	if err != nil {
+		return nil, err
+	}
+	return func(v table.Visitor[Key, Value]) error {
+		rows, err := t.selectRows(sel)
+		if err != nil {
+			return err
+		}
+		visit(rows, v)
+		return nil
+	}, nil
+}
+
+func (t *Table[Key, Value]) Keys() ([]Key, error) {
+	t.RLock()
+	defer t.RUnlock()
+	res, err := t.appendKeys(make([]Key, 0, t.rows.Len()), time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (t *Table[_, _]) Len() int {
+	t.RLock()
+	defer t.RUnlock()
+	return t.count(time.Now())
+}
+
+// appendKeys appends the Key of every row to res. The caller must hold at
+// least the read lock
+func (t *Table[Key, Value]) appendKeys(
+	res []Key, now time.Time,
+) ([]Key, error) {
+	err := t.rows.Each(func(k Key, _ []Value) bool {
+		if !t.isExpired(k, now) {
+			res = append(res, k)
+		}
+		return true
+	})
+	return res, err
+}
+
+// count returns the number of rows that haven't expired. The caller must hold
+// at least the read lock
+func (t *Table[_, _]) count(now time.Time) int {
+	return t.rows.Len() - t.countExpired(now)
+}
+
+// selectRows copies the selected column Values of every row while holding
+// the read lock, so that they can be visited without it
+func (t *Table[Key, Value]) selectRows(
+	sel *selection,
+) ([]*selected[Key, Value], error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	indexes, err := t.positions(sel)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*selected[Key, Value], 0, t.rows.Len())
+	res, err = t.appendRows(res, indexes, time.Now())
+	t.countReads(indexes, len(res))
+	return res, err
+}
+
+// selectAll copies every row, along with the names of its columns, while
+// holding the read lock
+func (t *Table[Key, Value]) selectAll() (
+	[]table.ColumnName, []*selected[Key, Value], error,
+) {
+	t.RLock()
+	defer t.RUnlock()
+
+	res := make([]*selected[Key, Value], 0, t.rows.Len())
+	rows, err := t.appendAll(res, time.Now())
+	return t.names, rows, err
+}
+
+// appendAll appends every row, along with its unset columns, to res. The
+// caller must hold at least the read lock
+func (t *Table[Key, Value]) appendAll(
+	res []*selected[Key, Value], now time.Time,
+) ([]*selected[Key, Value], error) {
+	start := len(res)
+	res, err := t.appendRows(res, t.allPositions(), now)
+	for _, r := range res[start:] {
+		r.unset = t.unset[r.key]
+	}
+	return res, err
+}
+
+// allPositions returns the position of every column. The caller must hold at
+// least the read lock
+func (t *Table[_, _]) allPositions() []int {
+	res := make([]int, len(t.names))
+	for i := range res {
+		res[i] = i
+	}
+	return res
+}
+
+// appendRows appends the selected column Values of every row to res. The
+// caller must hold at least the read lock
+func (t *Table[Key, Value]) appendRows(
+	res []*selected[Key, Value], indexes []int, now time.Time,
+) ([]*selected[Key, Value], error) {
+	err := t.rows.Each(func(k Key, row []Value) bool {
+		if !t.isExpired(k, now) {
+			res = append(res, selectRow(k, row, indexes))
+		}
+		return true
+	})
+	return res, err
+}
+
+// visit hands each selected row to the Visitor until it returns false
+func visit[Key comparable, Value any](
+	rows []*selected[Key, Value], v table.Visitor[Key, Value],
+) {
+	for _, r := range rows {
+		if !v(r.key, r.values) {
+			return
+		}
+	}
+}
+
+func selectRow[Key comparable, Value any](
+	k Key, row []Value, indexes []int,
+) *selected[Key, Value] {
+	values := make([]Value, len(indexes))
+	for out, in := range indexes {
+		values[out] = row[in]
+	}
+	return &selected[Key, Value]{
+		key:    k,
+		values: values,
+	}
+}
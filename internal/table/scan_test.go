@@ -0,0 +1,146 @@
+package table_test
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/config"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/streaming/internal/table"
+)
+
+func makeScanTable(t *testing.T) table.Table[int, any] {
+	tbl, err := internal.Make[int, any]("name", "age")
+	assert.Nil(t, err)
+	setter, _ := tbl.Setter("name", "age")
+	_ = setter(3, "carol", 47)
+	_ = setter(1, "bob", 42)
+	_ = setter(4, "dave", 51)
+	_ = setter(2, "june", 36)
+	return tbl
+}
+
+func collect[Key comparable, Value any](
+	t *testing.T, s table.Scanner[Key, Value],
+) ([]Key, [][]Value) {
+	var keys []Key
+	var rows [][]Value
+	assert.Nil(t, s(func(k Key, row []Value) bool {
+		keys = append(keys, k)
+		rows = append(rows, row)
+		return true
+	}))
+	return keys, rows
+}
+
+func TestKeysAndLen(t *testing.T) {
+	as := assert.New(t)
+
+	tbl := makeScanTable(t)
+	as.Equal(4, tbl.Len())
+	keys, err := tbl.Keys()
+	as.Nil(err)
+	sort.Ints(keys)
+	as.Equal([]int{1, 2, 3, 4}, keys)
+
+	as.Nil(tbl.Deleter()(3))
+	as.Equal(3, tbl.Len())
+	keys, err = tbl.Keys()
+	as.Nil(err)
+	sort.Ints(keys)
+	as.Equal([]int{1, 2, 4}, keys)
+}
+
+func TestScanner(t *testing.T) {
+	as := assert.New(t)
+
+	tbl := makeScanTable(t)
+	scan, err := tbl.Scanner("age")
+	as.NotNil(scan)
+	as.Nil(err)
+
+	keys, rows := collect(t, table.Sorted(scan))
+	as.Equal([]int{1, 2, 3, 4}, keys)
+	as.Equal([][]any{{42}, {36}, {47}, {51}}, rows)
+
+	keys, rows = collect(t, table.Sorted(scan.Where(
+		func(_ int, row []any) bool {
+			return row[0].(int) > 40
+		},
+	)))
+	as.Equal([]int{1, 3, 4}, keys)
+	as.Equal([][]any{{42}, {47}, {51}}, rows)
+
+	count := 0
+	as.Nil(scan(func(int, []any) bool {
+		count++
+		return false
+	}))
+	as.Equal(1, count)
+
+	scan, err = tbl.Scanner("missing")
+	as.Nil(scan)
+	as.EqualError(err, fmt.Sprintf(table.ErrColumnNotFound, "missing"))
+}
+
+func TestScannerWithoutLock(t *testing.T) {
+	as := assert.New(t)
+
+	tbl := makeScanTable(t)
+	scan, _ := tbl.Scanner("name", "age")
+	deleter := tbl.Deleter()
+
+	// The Table can be modified while visiting a scan
+	as.Nil(scan(func(k int, _ []any) bool {
+		as.Nil(deleter(k))
+		return true
+	}))
+	as.Zero(tbl.Len())
+}
+
+func TestRange(t *testing.T) {
+	as := assert.New(t)
+
+	tbl := makeScanTable(t)
+	scan, _ := tbl.Scanner("name")
+
+	keys, rows := collect(t, table.Range(scan, 2, 4))
+	as.Equal([]int{2, 3}, keys)
+	as.Equal([][]any{{"june"}, {"carol"}}, rows)
+
+	keys, _ = collect(t, table.Range(scan, 5, 10))
+	as.Nil(keys)
+
+	count := 0
+	as.Nil(table.Range(scan, 0, 10)(func(int, []any) bool {
+		count++
+		return count < 2
+	}))
+	as.Equal(2, count)
+}
+
+func TestScanExpired(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.MakeWith[string, any](
+		[]table.ColumnName{"name"},
+		config.TTL(10*time.Millisecond),
+		config.SweepInterval(time.Hour),
+	)
+	setter, _ := tbl.Setter("name")
+	as.Nil(setter("1", "bob"))
+	time.Sleep(20 * time.Millisecond)
+	as.Nil(setter("2", "june"))
+
+	as.Equal(1, tbl.Len())
+	keys, err := tbl.Keys()
+	as.Nil(err)
+	as.Equal([]string{"2"}, keys)
+	scan, _ := tbl.Scanner("name")
+	keys, _ = collect(t, scan)
+	as.Equal([]string{"2"}, keys)
+}
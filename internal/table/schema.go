@@ -0,0 +1,305 @@
+package table
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/caravan/streaming/table"
+)
+
+type (
+	// selection is the set of columns that a closure was created for. The
+	// positions of the columns within a row are resolved again whenever the
+	// Table's schema changes, so closures outlive schema changes
+	selection struct {
+		names    []table.ColumnName
+		resolved atomic.Pointer[resolved]
+	}
+
+	// resolved holds the positions of a selection's columns as of a
+	// particular schema
+	resolved struct {
+		schema  uint64
+		indexes []int
+		err     error
+	}
+
+	// schemaChange is a validated change to a Table's columns. Its rows are
+	// written first, and the rest of the Table is only updated once they
+	// all have been, so a change can be undone by putting back the old rows
+	schemaChange[Key comparable, Value any] struct {
+		tbl    *Table[Key, Value]
+		rows   []*rewrite[Key, Value]
+		update func()
+	}
+
+	// rewrite is a row of a schemaChange, before and after the change
+	rewrite[Key comparable, Value any] struct {
+		key      Key
+		old, new []Value
+	}
+)
+
+// selectColumns creates a selection of the specified columns, making sure
+// that they exist
+func (t *Table[_, _]) selectColumns(
+	c []table.ColumnName,
+) (*selection, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	indexes, err := t.columnIndexes(c)
+	if err != nil {
+		return nil, err
+	}
+	res := &selection{names: c}
+	res.resolved.Store(&resolved{
+		schema:  t.schema,
+		indexes: indexes,
+	})
+	return res, nil
+}
+
+// positions returns the positions of the selection's columns within the
+// Table's rows. A column that has since been dropped results in an error. The
+// caller must hold at least the read lock
+func (t *Table[_, _]) positions(s *selection) ([]int, error) {
+	if r := s.resolved.Load(); r.schema == t.schema {
+		return r.indexes, r.err
+	}
+	r := &resolved{schema: t.schema}
+	r.indexes = make([]int, len(s.names))
+	for i, n := range s.names {
+		idx, ok := t.indexes[n]
+		if !ok {
+			r.indexes, r.err = nil, fmt.Errorf(table.ErrColumnDropped, n)
+			break
+		}
+		r.indexes[i] = idx
+	}
+	s.resolved.Store(r)
+	return r.indexes, r.err
+}
+
+func (t *Table[Key, Value]) AddColumn(c table.ColumnName, v Value) error {
+	t.Lock()
+	err := t.changeSchema(t.planAddColumn(c, v))
+	evicted := t.takeEvictions()
+	t.Unlock()
+	t.notifyRemoved(evicted)
+	return err
+}
+
+// planAddColumn plans the appending of a column to every row, holding the
+// provided Value. The caller must hold the write lock
+func (t *Table[Key, Value]) planAddColumn(
+	c table.ColumnName, v Value,
+) (*schemaChange[Key, Value], error) {
+	if err := t.checkSchemaChange(); err != nil {
+		return nil, err
+	}
+	if _, ok := t.indexes[c]; ok {
+		return nil, fmt.Errorf(table.ErrDuplicateColumnName, c)
+	}
+	pos := len(t.names)
+	return t.planRows(func(row []Value) []Value {
+		res := make([]Value, pos+1)
+		copy(res, row)
+		res[pos] = v
+		return res
+	}, func() {
+		t.names = append(t.names[:pos:pos], c)
+		t.usage = append(t.usage[:pos:pos], &usage{})
+		t.indexes = columnPositions(t.names)
+		defaults := make([]Value, pos+1)
+		copy(defaults, t.defaults)
+		defaults[pos] = v
+		t.defaults = defaults
+		if t.merges != nil {
+			t.merges = append(t.merges[:pos:pos], nil)
+		}
+		for _, k := range t.keys() {
+			t.unset[k] = t.unset[k].with(pos, pos+1)
+		}
+	})
+}
+
+func (t *Table[Key, Value]) DropColumn(c table.ColumnName) error {
+	t.Lock()
+	err := t.changeSchema(t.planDropColumn(c))
+	evicted := t.takeEvictions()
+	t.Unlock()
+	t.notifyRemoved(evicted)
+	return err
+}
+
+// planDropColumn plans the removal of a column from every row. The caller
+// must hold the write lock
+func (t *Table[Key, Value]) planDropColumn(
+	c table.ColumnName,
+) (*schemaChange[Key, Value], error) {
+	if err := t.checkSchemaChange(); err != nil {
+		return nil, err
+	}
+	pos, ok := t.indexes[c]
+	if !ok {
+		return nil, fmt.Errorf(table.ErrColumnNotFound, c)
+	}
+	for _, ix := range t.secondary {
+		for _, col := range ix.columns {
+			if col == pos {
+				return nil, fmt.Errorf(table.ErrColumnIndexed, c)
+			}
+		}
+	}
+	return t.planRows(func(row []Value) []Value {
+		return without(row, pos)
+	}, func() {
+		t.names = without(t.names, pos)
+		t.usage = without(t.usage, pos)
+		t.indexes = columnPositions(t.names)
+		if t.defaults != nil {
+			t.defaults = without(t.defaults, pos)
+		}
+		if t.merges != nil {
+			t.merges = without(t.merges, pos)
+		}
+		for k, u := range t.unset {
+			t.storeUnset(k, u.without(pos, len(t.names)+1))
+		}
+		for _, ix := range t.secondary {
+			for i, col := range ix.columns {
+				if col > pos {
+					ix.columns[i] = col - 1
+				}
+			}
+		}
+	})
+}
+
+// checkSchemaChange makes sure that the Table's schema can be changed. The
+// rows of a changelog are positional, so replaying one that spans a schema
+// change would misplace Values
+func (t *Table[_, _]) checkSchemaChange() error {
+	if t.changelog != nil {
+		return errors.New(table.ErrSchemaChangelog)
+	}
+	return nil
+}
+
+// changeSchema writes the planned change to the Table, or returns the error
+// that kept it from being planned or written. The caller must hold the write
+// lock
+func (t *Table[Key, Value]) changeSchema(
+	ch *schemaChange[Key, Value], err error,
+) error {
+	if err != nil {
+		return err
+	}
+	if err := ch.write(); err != nil {
+		return err
+	}
+	ch.commit()
+	return nil
+}
+
+// planRows captures every row in the Table's Store, along with the result of
+// passing it to fn, so that every new row is built before any is written.
+// Rows are replaced rather than modified, because they may have been handed
+// out in a Change. The update brings the rest of the Table in line with the
+// new rows once they've all been written. The caller must hold the write lock
+func (t *Table[Key, Value]) planRows(
+	fn func([]Value) []Value, update func(),
+) (*schemaChange[Key, Value], error) {
+	res := &schemaChange[Key, Value]{
+		tbl:    t,
+		update: update,
+	}
+	err := t.rows.Each(func(k Key, row []Value) bool {
+		res.rows = append(res.rows, &rewrite[Key, Value]{key: k, old: row})
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range res.rows {
+		r.new = fn(r.old)
+	}
+	return res, nil
+}
+
+// write replaces the Table's rows with the planned ones. If a row can't be
+// written, the rows that already were are put back, so that they all keep the
+// same width. Rows evicted in the meantime stay evicted
+func (ch *schemaChange[Key, Value]) write() error {
+	t := ch.tbl
+	for i, r := range ch.rows {
+		if _, ok := t.versions[r.key]; !ok {
+			continue // evicted while making room for a rewritten row
+		}
+		if err := t.rows.Put(r.key, r.new); err != nil {
+			restoreRows(t, ch.rows[:i])
+			return err
+		}
+		t.resize(t.sizeOf(r.key, r.new), t.sizeOf(r.key, r.old))
+	}
+	return nil
+}
+
+// undo puts back the rows of a change that has been written but not
+// committed, because the same change failed elsewhere
+func (ch *schemaChange[Key, Value]) undo() {
+	restoreRows(ch.tbl, ch.rows)
+}
+
+// commit updates the rest of the Table once its rows have been written
+func (ch *schemaChange[Key, Value]) commit() {
+	ch.update()
+	ch.tbl.schema++
+	ch.tbl.markDirty()
+}
+
+// restoreRows puts back the old rows of the rewrites, in reverse order. The
+// original failure is what's returned to the caller, so one that leaves the
+// rows mixed is reported by Close
+func restoreRows[Key comparable, Value any](
+	t *Table[Key, Value], rows []*rewrite[Key, Value],
+) {
+	for i := len(rows) - 1; i >= 0; i-- {
+		r := rows[i]
+		if _, ok := t.versions[r.key]; !ok {
+			continue
+		}
+		if err := t.rows.Put(r.key, r.old); err != nil {
+			t.fail(err)
+			continue
+		}
+		t.resize(t.sizeOf(r.key, r.old), t.sizeOf(r.key, r.new))
+	}
+}
+
+// keys returns the Key of every row, including those that have expired but
+// haven't been swept. The caller must hold at least the read lock
+func (t *Table[Key, _]) keys() []Key {
+	res := make([]Key, 0, len(t.versions))
+	for k := range t.versions {
+		res = append(res, k)
+	}
+	return res
+}
+
+func columnPositions(c []table.ColumnName) map[table.ColumnName]int {
+	res := make(map[table.ColumnName]int, len(c))
+	for i, n := range c {
+		res[n] = i
+	}
+	return res
+}
+
+// without returns a copy of the slice with the element at pos removed
+func without[T any](s []T, pos int) []T {
+	res := make([]T, 0, len(s)-1)
+	res = append(res, s[:pos]...)
+	return append(res, s[pos+1:]...)
+}
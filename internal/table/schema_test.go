@@ -0,0 +1,297 @@
+package table_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/config"
+	"github.com/caravan/streaming/table/merge"
+	"github.com/caravan/streaming/table/store"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/streaming/internal/table"
+)
+
+func TestAddColumn(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.Make[string, any]("name", "age")
+	setter, _ := tbl.Setter("name", "age")
+	getter, _ := tbl.Getter("age", "name")
+	as.Nil(setter("1", "bob", 42))
+
+	as.Nil(tbl.AddColumn("city", "unknown"))
+	as.Equal([]table.ColumnName{"name", "age", "city"}, tbl.Columns())
+
+	// Existing closures keep working
+	res, err := getter("1")
+	as.Nil(err)
+	as.Equal([]any{42, "bob"}, res)
+	as.Nil(setter("2", "june", 36))
+
+	// Existing and new rows hold the default, but it isn't set
+	presence, _ := tbl.PresenceGetter("name", "city")
+	res, set, _ := presence("1")
+	as.Equal([]any{"bob", "unknown"}, res)
+	as.Equal([]bool{true, false}, set)
+	res, set, _ = presence("2")
+	as.Equal([]any{"june", "unknown"}, res)
+	as.Equal([]bool{true, false}, set)
+
+	setCity, _ := tbl.Setter("city")
+	as.Nil(setCity("1", "paris"))
+	res, set, _ = presence("1")
+	as.Equal([]any{"bob", "paris"}, res)
+	as.Equal([]bool{true, true}, set)
+
+	as.EqualError(tbl.AddColumn("name", nil),
+		fmt.Sprintf(table.ErrDuplicateColumnName, "name"),
+	)
+}
+
+func TestDropColumn(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.MakeWith[string, any](
+		[]table.ColumnName{"name", "age", "city", "visits"},
+		config.Index("city"),
+		config.Merge("visits", merge.Sum[any]()),
+		config.ColumnDefault("visits", 0),
+	)
+	setter, _ := tbl.Setter("name", "age", "city")
+	getAge, _ := tbl.Getter("age")
+	getRest, _ := tbl.Getter("visits", "city", "name")
+	setVisits, _ := tbl.Setter("visits")
+	as.Nil(setter("1", "bob", 42, "paris"))
+	as.Nil(setVisits("1", 1))
+
+	as.Nil(tbl.DropColumn("age"))
+	as.Equal([]table.ColumnName{"name", "city", "visits"}, tbl.Columns())
+
+	// Closures that reference the dropped column report it
+	_, err := getAge("1")
+	as.EqualError(err, fmt.Sprintf(table.ErrColumnDropped, "age"))
+	err = setter("2", "june", 36, "rome")
+	as.EqualError(err, fmt.Sprintf(table.ErrColumnDropped, "age"))
+
+	// Others keep working, along with indexes, merges, and defaults
+	res, err := getRest("1")
+	as.Nil(err)
+	as.Equal([]any{1, "paris", "bob"}, res)
+	as.Nil(setVisits("1", 2))
+	res, _ = getRest("1")
+	as.Equal([]any{3, "paris", "bob"}, res)
+
+	find, _ := tbl.Finder([]table.ColumnName{"city"}, "name")
+	keys, rows, _ := find("paris")
+	as.Equal([]string{"1"}, keys)
+	as.Equal([][]any{{"bob"}}, rows)
+
+	setCity, _ := tbl.Setter("city")
+	as.Nil(setCity("2", "rome"))
+	presence, _ := tbl.PresenceGetter("name", "city", "visits")
+	res, set, _ := presence("2")
+	as.Equal([]any{nil, "rome", 0}, res)
+	as.Equal([]bool{false, true, false}, set)
+
+	// Snapshots are written with the new schema
+	var buf bytes.Buffer
+	as.Nil(tbl.Snapshot(&buf))
+	path := t.TempDir() + "/snapshot"
+	as.Nil(os.WriteFile(path, buf.Bytes(), 0o644))
+	restored, err := internal.MakeWith[string, any](
+		[]table.ColumnName{"name", "city", "visits"},
+		config.SnapshotFile(path),
+	)
+	as.Nil(err)
+	as.Equal(2, restored.Len())
+
+	as.EqualError(tbl.DropColumn("city"),
+		fmt.Sprintf(table.ErrColumnIndexed, "city"),
+	)
+	as.EqualError(tbl.DropColumn("age"),
+		fmt.Sprintf(table.ErrColumnNotFound, "age"),
+	)
+
+	// Adding the column back makes it available to closures again
+	as.Nil(tbl.AddColumn("age", nil))
+	res, err = getAge("1")
+	as.Nil(err)
+	as.Equal([]any{nil}, res)
+}
+
+func TestSchemaChangelog(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.MakeWith[string, any](
+		[]table.ColumnName{"name"},
+		config.ChangelogFile(t.TempDir()+"/changelog"),
+	)
+	as.EqualError(tbl.AddColumn("age", 0), table.ErrSchemaChangelog)
+	as.EqualError(tbl.DropColumn("name"), table.ErrSchemaChangelog)
+}
+
+func TestShardedSchema(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.MakeWith[int, any](
+		[]table.ColumnName{"name", "age"},
+		config.Shards(4),
+	)
+	setter, _ := tbl.Setter("name", "age")
+	for i := 0; i < 20; i++ {
+		as.Nil(setter(i, "bob", i))
+	}
+	getter, _ := tbl.Getter("age")
+	scan, _ := tbl.Scanner("age")
+
+	as.Nil(tbl.DropColumn("name"))
+	as.Nil(tbl.AddColumn("city", "paris"))
+	as.Equal([]table.ColumnName{"age", "city"}, tbl.Columns())
+
+	res, err := getter(7)
+	as.Nil(err)
+	as.Equal([]any{7}, res)
+	sum := 0
+	as.Nil(scan(func(k int, row []any) bool {
+		as.Equal(k, row[0])
+		sum += row[0].(int)
+		return true
+	}))
+	as.Equal(190, sum)
+
+	city, _ := tbl.Getter("city")
+	res, _ = city(3)
+	as.Equal([]any{"paris"}, res)
+
+	as.EqualError(tbl.DropColumn("missing"),
+		fmt.Sprintf(table.ErrColumnNotFound, "missing"),
+	)
+}
+
+func TestFailedSchemaChange(t *testing.T) {
+	as := assert.New(t)
+
+	s := &failingStore{}
+	tbl, _ := internal.MakeWith[string, any](
+		[]table.ColumnName{"name"},
+		config.Store(failing(s)),
+	)
+	setter, _ := tbl.Setter("name")
+	for i := 0; i < 10; i++ {
+		as.Nil(setter(fmt.Sprint(i), "bob"))
+	}
+
+	// The rows that were already rewritten are put back
+	s.rejected = "5"
+	as.Equal(errStoreFailed, tbl.AddColumn("age", 0))
+	as.Equal([]table.ColumnName{"name"}, tbl.Columns())
+	as.Nil(s.Store.Each(func(_ string, row []any) bool {
+		as.Equal([]any{"bob"}, row)
+		return true
+	}))
+	as.Nil(tbl.Close())
+}
+
+func TestFailedShardedSchemaChange(t *testing.T) {
+	as := assert.New(t)
+
+	// Only the last shard's Store fails, so the others are written first
+	last := &failingStore{}
+	var stores []store.Store[string, any]
+	tbl, _ := internal.MakeWith[string, any](
+		[]table.ColumnName{"name"},
+		config.Shards(4),
+		config.Store(func(shard int) (store.Store[string, any], error) {
+			if shard == 3 {
+				last.Store = store.NewMap[string, any]()
+				stores = append(stores, last.Store)
+				return last, nil
+			}
+			res := store.NewMap[string, any]()
+			stores = append(stores, res)
+			return res, nil
+		}),
+	)
+	setter, _ := tbl.Setter("name")
+	for i := 0; i < 40; i++ {
+		as.Nil(setter(fmt.Sprint(i), "bob"))
+	}
+	as.Nil(last.Store.Each(func(k string, _ []any) bool {
+		last.rejected = k
+		return false
+	}))
+	as.NotEmpty(last.rejected)
+
+	as.Equal(errStoreFailed, tbl.AddColumn("age", 0))
+	as.Equal([]table.ColumnName{"name"}, tbl.Columns())
+	for _, s := range stores {
+		as.Nil(s.Each(func(_ string, row []any) bool {
+			as.Equal([]any{"bob"}, row)
+			return true
+		}))
+	}
+
+	last.rejected = ""
+	as.Nil(tbl.AddColumn("age", 0))
+	getter, _ := tbl.Getter("name", "age")
+	res, err := getter("7")
+	as.Nil(err)
+	as.Equal([]any{"bob", 0}, res)
+	as.Nil(tbl.Close())
+}
+
+func TestConcurrentSchemaChange(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.Make[int, any]("name")
+	setter, _ := tbl.Setter("name")
+	getter, _ := tbl.Getter("name")
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			as.Nil(tbl.AddColumn(table.ColumnName(fmt.Sprint(i)), i))
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		as.Nil(setter(i, "bob"))
+		res, err := getter(i)
+		as.Nil(err)
+		as.Equal([]any{"bob"}, res)
+	}
+	wg.Wait()
+	as.Equal(101, len(tbl.Columns()))
+}
+
+func TestOrderedSchema(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.MakeOrdered[int, any]([]table.ColumnName{"a", "b"})
+	setter, _ := tbl.Setter("a", "b")
+	as.Nil(setter(1, "a1", "b1"))
+	as.Nil(setter(2, "a2", "b2"))
+
+	rangeB, _ := tbl.Ranger("b")
+	rangeA, _ := tbl.Ranger("a")
+	as.Nil(tbl.DropColumn("a"))
+
+	k, row, ok, err := rangeB.Min()
+	as.Nil(err)
+	as.True(ok)
+	as.Equal(1, k)
+	as.Equal([]any{"b1"}, row)
+
+	_, _, ok, err = rangeA.Max()
+	as.False(ok)
+	as.EqualError(err, fmt.Sprintf(table.ErrColumnDropped, "a"))
+	as.EqualError(rangeA.From(0)(func(int, []any) bool {
+		return true
+	}), fmt.Sprintf(table.ErrColumnDropped, "a"))
+}
@@ -0,0 +1,663 @@
+package table
+
+import (
+	"errors"
+	"fmt"
+	"hash/maphash"
+	"io"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/caravan/essentials"
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/config"
+
+	topicConfig "github.com/caravan/essentials/topic/config"
+)
+
+type (
+	// Sharded is the internal implementation of a table.Table that splits
+	// its rows across several Tables, each with its own lock. A Key always
+	// belongs to the same shard, so operations on a single Key only lock
+	// that shard. Operations that span the whole Table lock every shard, in
+	// order, so they see a consistent view of it
+	Sharded[Key comparable, Value any] struct {
+		shards []*Table[Key, Value]
+		seed   maphash.Seed
+	}
+
+	// shardedTxn is a transaction's view of a Sharded Table. It holds a txn
+	// for each of the Table's shards
+	shardedTxn[Key comparable, Value any] struct {
+		*transaction
+		tbl  *Sharded[Key, Value]
+		txns []*txn[Key, Value]
+	}
+)
+
+func makeSharded[Key comparable, Value any](
+	c []table.ColumnName, cfg *config.Config,
+) (*Sharded[Key, Value], error) {
+	if cfg.SnapshotFile != "" || cfg.Changelog != nil ||
+		cfg.ChangelogFile != "" {
+		return nil, errors.New(config.ErrShardedPersistence)
+	}
+	res := &Sharded[Key, Value]{
+		shards: make([]*Table[Key, Value], cfg.Shards),
+		seed:   maphash.MakeSeed(),
+	}
+	for i := range res.shards {
+		rows, err := makeStore[Key, Value](cfg, i)
+		if err == nil {
+			res.shards[i], err = makeTable[Key, Value](c, rows, cfg)
+			if err != nil {
+				_ = rows.Close()
+			}
+		}
+		if err != nil {
+			for _, t := range res.shards[:i] {
+				_ = t.Close()
+			}
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (s *Sharded[_, _]) Columns() []table.ColumnName {
+	return s.shards[0].Columns()
+}
+
+func (s *Sharded[Key, Value]) Getter(
+	c ...table.ColumnName,
+) (table.Getter[Key, Value], error) {
+	getters, err := eachShard(s.shards,
+		func(t *Table[Key, Value]) (table.Getter[Key, Value], error) {
+			return t.Getter(c...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return func(k Key) ([]Value, error) {
+		return getters[s.shardOf(k)](k)
+	}, nil
+}
+
+// MultiGetter groups the Keys by shard, so each shard's lock is only acquired
+// once per call
+func (s *Sharded[Key, Value]) MultiGetter(
+	c ...table.ColumnName,
+) (table.MultiGetter[Key, Value], error) {
+	getters, err := eachShard(s.shards,
+		func(t *Table[Key, Value]) (table.MultiGetter[Key, Value], error) {
+			return t.MultiGetter(c...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return func(keys ...Key) (map[Key][]Value, []Key, error) {
+		byShard := make([][]Key, len(s.shards))
+		for _, k := range keys {
+			i := s.shardOf(k)
+			byShard[i] = append(byShard[i], k)
+		}
+		res := make(map[Key][]Value, len(keys))
+		for i, shardKeys := range byShard {
+			if len(shardKeys) == 0 {
+				continue
+			}
+			rows, _, err := getters[i](shardKeys...)
+			if err != nil {
+				return nil, nil, err
+			}
+			for k, row := range rows {
+				res[k] = row
+			}
+		}
+		var missing []Key
+		for _, k := range keys {
+			if _, ok := res[k]; !ok {
+				missing = append(missing, k)
+			}
+		}
+		return res, missing, nil
+	}, nil
+}
+
+func (s *Sharded[Key, Value]) VersionedGetter(
+	c ...table.ColumnName,
+) (table.VersionedGetter[Key, Value], error) {
+	getters, err := eachShard(s.shards,
+		func(t *Table[Key, Value]) (table.VersionedGetter[Key, Value], error) {
+			return t.VersionedGetter(c...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return func(k Key) ([]Value, table.Version, error) {
+		return getters[s.shardOf(k)](k)
+	}, nil
+}
+
+func (s *Sharded[Key, Value]) PresenceGetter(
+	c ...table.ColumnName,
+) (table.PresenceGetter[Key, Value], error) {
+	getters, err := eachShard(s.shards,
+		func(t *Table[Key, Value]) (table.PresenceGetter[Key, Value], error) {
+			return t.PresenceGetter(c...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return func(k Key) ([]Value, []bool, error) {
+		return getters[s.shardOf(k)](k)
+	}, nil
+}
+
+func (s *Sharded[Key, Value]) Setter(
+	c ...table.ColumnName,
+) (table.Setter[Key, Value], error) {
+	setters, err := eachShard(s.shards,
+		func(t *Table[Key, Value]) (table.Setter[Key, Value], error) {
+			return t.Setter(c...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return func(k Key, v ...Value) error {
+		return setters[s.shardOf(k)](k, v...)
+	}, nil
+}
+
+func (s *Sharded[Key, Value]) CompareAndSetter(
+	c ...table.ColumnName,
+) (table.CompareAndSetter[Key, Value], error) {
+	setters, err := eachShard(s.shards,
+		func(t *Table[Key, Value]) (table.CompareAndSetter[Key, Value], error) {
+			return t.CompareAndSetter(c...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return func(k Key, expected table.Version, v ...Value) (
+		table.Version, error,
+	) {
+		return setters[s.shardOf(k)](k, expected, v...)
+	}, nil
+}
+
+func (s *Sharded[Key, Value]) AddColumn(c table.ColumnName, v Value) error {
+	return s.changeSchema(func(t *Table[Key, Value]) (
+		*schemaChange[Key, Value], error,
+	) {
+		return t.planAddColumn(c, v)
+	})
+}
+
+func (s *Sharded[Key, Value]) DropColumn(c table.ColumnName) error {
+	return s.changeSchema(func(t *Table[Key, Value]) (
+		*schemaChange[Key, Value], error,
+	) {
+		return t.planDropColumn(c)
+	})
+}
+
+// changeSchema applies a schema change to every shard while holding all of
+// their write locks, so that no closure sees the shards disagree. The change
+// is planned for every shard before any is written, and if a shard's Store
+// fails, the shards that were already written are put back, so that either
+// every shard changes or none does
+func (s *Sharded[Key, Value]) changeSchema(
+	plan func(*Table[Key, Value]) (*schemaChange[Key, Value], error),
+) error {
+	for _, t := range s.shards {
+		t.Lock()
+	}
+	res := s.applySchema(plan)
+	evicted := make([][]*removal[Key, Value], len(s.shards))
+	for i, t := range s.shards {
+		evicted[i] = t.takeEvictions()
+		t.Unlock()
+	}
+	for i, t := range s.shards {
+		t.notifyRemoved(evicted[i])
+	}
+	return res
+}
+
+// applySchema plans, writes, and then commits a schema change to every
+// shard. The caller must hold every shard's write lock
+func (s *Sharded[Key, Value]) applySchema(
+	plan func(*Table[Key, Value]) (*schemaChange[Key, Value], error),
+) error {
+	changes := make([]*schemaChange[Key, Value], len(s.shards))
+	for i, t := range s.shards {
+		ch, err := plan(t)
+		if err != nil {
+			return err
+		}
+		changes[i] = ch
+	}
+	for i, ch := range changes {
+		if err := ch.write(); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				changes[j].undo()
+			}
+			return err
+		}
+	}
+	for _, ch := range changes {
+		ch.commit()
+	}
+	return nil
+}
+
+func (s *Sharded[Key, _]) Deleter() table.Deleter[Key] {
+	deleters := make([]table.Deleter[Key], len(s.shards))
+	for i, t := range s.shards {
+		deleters[i] = t.Deleter()
+	}
+	return func(k Key) error {
+		return deleters[s.shardOf(k)](k)
+	}
+}
+
+func (s *Sharded[Key, Value]) Finder(
+	idx []table.ColumnName, c ...table.ColumnName,
+) (table.Finder[Key, Value], error) {
+	finders, err := eachShard(s.shards,
+		func(t *Table[Key, Value]) (table.Finder[Key, Value], error) {
+			return t.Finder(idx, c...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return func(v ...Value) ([]Key, [][]Value, error) {
+		var resKeys []Key
+		var resValues [][]Value
+		for _, find := range finders {
+			keys, values, err := find(v...)
+			if err != nil {
+				return nil, nil, err
+			}
+			resKeys = append(resKeys, keys...)
+			resValues = append(resValues, values...)
+		}
+		return resKeys, resValues, nil
+	}, nil
+}
+
+func (s *Sharded[Key, Value]) Scanner(
+	c ...table.ColumnName,
+) (table.Scanner[Key, Value], error) {
+	sel, err := s.shards[0].selectColumns(c)
+	if err != nil {
+		return nil, err
+	}
+	return func(v table.Visitor[Key, Value]) error {
+		rows, err := s.selectRows(sel)
+		if err != nil {
+			return err
+		}
+		visit(rows, v)
+		return nil
+	}, nil
+}
+
+func (s *Sharded[Key, _]) Keys() ([]Key, error) {
+	s.rLockAll()
+	defer s.rUnlockAll()
+
+	now := time.Now()
+	var res []Key
+	for _, t := range s.shards {
+		var err error
+		if res, err = t.appendKeys(res, now); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (s *Sharded[_, _]) Len() int {
+	s.rLockAll()
+	defer s.rUnlockAll()
+
+	now := time.Now()
+	res := 0
+	for _, t := range s.shards {
+		res += t.count(now)
+	}
+	return res
+}
+
+// Stats combines the Stats of every shard. The shards are visited one at a
+// time, so the result isn't a consistent point-in-time view of the Table
+func (s *Sharded[_, _]) Stats() (table.Stats, error) {
+	res := table.Stats{
+		Columns: map[table.ColumnName]table.ColumnStats{},
+	}
+	for _, t := range s.shards {
+		st, err := t.Stats()
+		if err != nil {
+			return table.Stats{}, err
+		}
+		res.Rows += st.Rows
+		res.Bytes += st.Bytes
+		res.Lookups.Hits += st.Lookups.Hits
+		res.Lookups.Misses += st.Lookups.Misses
+		res.LockWait += st.LockWait
+		for n, c := range st.Columns {
+			sum := res.Columns[n]
+			sum.Reads += c.Reads
+			sum.Writes += c.Writes
+			res.Columns[n] = sum
+		}
+	}
+	return res, nil
+}
+
+// Changes returns a Consumer of a Topic that every shard publishes to. The
+// Changes to a single Key arrive in order, but the Changes to Keys in
+// different shards may be interleaved in any order
+func (s *Sharded[Key, Value]) Changes() topic.Consumer[*table.Change[Key, Value]] {
+	top := essentials.NewTopic[*table.Change[Key, Value]](
+		topicConfig.Consumed,
+	)
+	c := top.NewConsumer()
+	for _, t := range s.shards {
+		t.watch(&watcher[Key, Value]{
+			producer: top.NewProducer(),
+			consumer: c,
+		})
+	}
+	return c
+}
+
+func (s *Sharded[Key, Value]) Snapshot(w io.Writer) error {
+	names, rows, err := s.selectAll()
+	if err != nil {
+		return err
+	}
+	return s.shards[0].writeSnapshot(w, names, rows)
+}
+
+// Close closes every shard, reporting the first failure
+func (s *Sharded[_, _]) Close() error {
+	var res error
+	for _, t := range s.shards {
+		if err := t.Close(); err != nil && res == nil {
+			res = err
+		}
+	}
+	return res
+}
+
+func (s *Sharded[Key, Value]) Begin() table.Txn[Key, Value] {
+	return s.join(&transaction{})
+}
+
+func (s *Sharded[Key, Value]) Join(
+	tx table.Transaction,
+) (table.Txn[Key, Value], error) {
+	m, ok := tx.(transactional)
+	if !ok {
+		return nil, fmt.Errorf(table.ErrTransactionType, tx)
+	}
+	tr := m.joined()
+	if tr.finished {
+		return nil, errors.New(table.ErrTransactionFinished)
+	}
+	var txns []*txn[Key, Value]
+	for _, m := range tr.members {
+		if x, ok := m.(*txn[Key, Value]); ok && s.owns(x.tbl) {
+			txns = append(txns, x)
+		}
+	}
+	if len(txns) != 0 {
+		return s.view(tr, txns), nil
+	}
+	return s.join(tr), nil
+}
+
+func (s *Sharded[Key, Value]) join(tr *transaction) *shardedTxn[Key, Value] {
+	txns := make([]*txn[Key, Value], len(s.shards))
+	for i, t := range s.shards {
+		txns[i] = t.join(tr)
+	}
+	return s.view(tr, txns)
+}
+
+func (s *Sharded[Key, Value]) view(
+	tr *transaction, txns []*txn[Key, Value],
+) *shardedTxn[Key, Value] {
+	return &shardedTxn[Key, Value]{
+		transaction: tr,
+		tbl:         s,
+		txns:        txns,
+	}
+}
+
+func (s *Sharded[Key, Value]) owns(t *Table[Key, Value]) bool {
+	for _, shard := range s.shards {
+		if shard == t {
+			return true
+		}
+	}
+	return false
+}
+
+// selectRows copies the selected column Values of every row in every shard
+// while holding all of their read locks
+func (s *Sharded[Key, Value]) selectRows(
+	sel *selection,
+) ([]*selected[Key, Value], error) {
+	s.rLockAll()
+	defer s.rUnlockAll()
+
+	// Every shard shares the same schema, so positions resolved against
+	// one are good for all of them
+	indexes, err := s.shards[0].positions(sel)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var res []*selected[Key, Value]
+	for _, t := range s.shards {
+		n := len(res)
+		if res, err = t.appendRows(res, indexes, now); err != nil {
+			return res, err
+		}
+		t.countReads(indexes, len(res)-n)
+	}
+	return res, nil
+}
+
+// selectAll copies every row in every shard, along with the names of its
+// columns, while holding all of their read locks
+func (s *Sharded[Key, Value]) selectAll() (
+	[]table.ColumnName, []*selected[Key, Value], error,
+) {
+	s.rLockAll()
+	defer s.rUnlockAll()
+
+	now := time.Now()
+	var res []*selected[Key, Value]
+	for _, t := range s.shards {
+		var err error
+		if res, err = t.appendAll(res, now); err != nil {
+			return nil, nil, err
+		}
+	}
+	return s.shards[0].names, res, nil
+}
+
+func (s *Sharded[_, _]) rLockAll() {
+	for _, t := range s.shards {
+		t.RLock()
+	}
+}
+
+func (s *Sharded[_, _]) rUnlockAll() {
+	for i := len(s.shards) - 1; i >= 0; i-- {
+		s.shards[i].RUnlock()
+	}
+}
+
+// shardOf returns the index of the shard that the Key belongs to
+func (s *Sharded[Key, _]) shardOf(k Key) int {
+	return int(hashKey(s.seed, k) % uint64(len(s.shards)))
+}
+
+func (x *shardedTxn[Key, Value]) Getter(
+	c ...table.ColumnName,
+) (table.Getter[Key, Value], error) {
+	getters, err := eachShard(x.txns,
+		func(t *txn[Key, Value]) (table.Getter[Key, Value], error) {
+			return t.Getter(c...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return func(k Key) ([]Value, error) {
+		return getters[x.tbl.shardOf(k)](k)
+	}, nil
+}
+
+func (x *shardedTxn[Key, Value]) Setter(
+	c ...table.ColumnName,
+) (table.Setter[Key, Value], error) {
+	setters, err := eachShard(x.txns,
+		func(t *txn[Key, Value]) (table.Setter[Key, Value], error) {
+			return t.Setter(c...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return func(k Key, v ...Value) error {
+		return setters[x.tbl.shardOf(k)](k, v...)
+	}, nil
+}
+
+func (x *shardedTxn[Key, _]) Deleter() table.Deleter[Key] {
+	deleters := make([]table.Deleter[Key], len(x.txns))
+	for i, t := range x.txns {
+		deleters[i] = t.Deleter()
+	}
+	return func(k Key) error {
+		return deleters[x.tbl.shardOf(k)](k)
+	}
+}
+
+// eachShard creates a function for each shard, failing if any of them can't
+// be created
+func eachShard[Shard, Fn any](
+	shards []Shard, create func(Shard) (Fn, error),
+) ([]Fn, error) {
+	res := make([]Fn, len(shards))
+	for i, s := range shards {
+		fn, err := create(s)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = fn
+	}
+	return res, nil
+}
+
+// hashKey hashes a comparable Key so that Keys that are equal always have the
+// same hash
+func hashKey(seed maphash.Seed, k any) uint64 {
+	switch k := k.(type) {
+	case string:
+		return maphash.String(seed, k)
+	case int:
+		return mix(uint64(k))
+	}
+	return hashValue(seed, reflect.ValueOf(k))
+}
+
+// hashValue hashes a value the way the == operator compares it. Pointers and
+// channels are hashed by address, and structs, arrays and interfaces by the
+// values they hold
+func hashValue(seed maphash.Seed, v reflect.Value) uint64 {
+	switch v.Kind() {
+	case reflect.String:
+		return maphash.String(seed, v.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return mix(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return mix(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return hashFloat(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		return combine(hashFloat(real(c)), hashFloat(imag(c)))
+	case reflect.Bool:
+		if v.Bool() {
+			return mix(1)
+		}
+		return mix(0)
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		return mix(uint64(v.Pointer()))
+	case reflect.Interface:
+		if v.IsNil() {
+			return mix(0)
+		}
+		return hashValue(seed, v.Elem())
+	case reflect.Array:
+		var res uint64
+		for i := 0; i < v.Len(); i++ {
+			res = combine(res, hashValue(seed, v.Index(i)))
+		}
+		return res
+	case reflect.Struct:
+		var res uint64
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if t.Field(i).Name != "_" { // blank fields aren't compared
+				res = combine(res, hashValue(seed, v.Field(i)))
+			}
+		}
+		return res
+	default:
+		// Only invalid values remain, such as a nil interface Key
+		return mix(0)
+	}
+}
+
+func hashFloat(f float64) uint64 {
+	if f == 0 {
+		f = 0 // -0 is equal to 0, so it must hash the same way
+	}
+	return mix(math.Float64bits(f))
+}
+
+// combine folds the hash of a part of a value into the hash of the whole
+func combine(h, x uint64) uint64 {
+	return mix(h ^ (x + 0x9e3779b97f4a7c15 + h<<6 + h>>2))
+}
+
+// mix spreads the bits of an integer Key, so that sequential Keys are spread
+// evenly across shards
+func mix(x uint64) uint64 {
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
@@ -0,0 +1,292 @@
+package table_test
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/config"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/streaming/internal/table"
+)
+
+func makeShardedTable(t *testing.T) table.Table[int, any] {
+	tbl, err := internal.MakeWith[int, any](
+		[]table.ColumnName{"name", "age"},
+		config.Shards(4),
+		config.Index("age"),
+	)
+	assert.Nil(t, err)
+	setter, _ := tbl.Setter("name", "age")
+	for i := 0; i < 100; i++ {
+		assert.Nil(t, setter(i, strconv.Itoa(i), i%10))
+	}
+	return tbl
+}
+
+func TestSharded(t *testing.T) {
+	as := assert.New(t)
+
+	tbl := makeShardedTable(t)
+	_, ok := tbl.(*internal.Sharded[int, any])
+	as.True(ok)
+	as.Equal([]table.ColumnName{"name", "age"}, tbl.Columns())
+	as.Equal(100, tbl.Len())
+	keys, err := tbl.Keys()
+	as.Nil(err)
+	sort.Ints(keys)
+	as.Len(keys, 100)
+	as.Equal(0, keys[0])
+	as.Equal(99, keys[99])
+
+	getter, err := tbl.Getter("name")
+	as.Nil(err)
+	res, err := getter(42)
+	as.Nil(err)
+	as.Equal([]any{"42"}, res)
+	_, err = getter(100)
+	as.EqualError(err, fmt.Sprintf(table.ErrKeyNotFound, 100))
+
+	as.Nil(tbl.Deleter()(42))
+	as.Equal(99, tbl.Len())
+	_, err = getter(42)
+	as.EqualError(err, fmt.Sprintf(table.ErrKeyNotFound, 42))
+
+	vGetter, _ := tbl.VersionedGetter("name")
+	cas, _ := tbl.CompareAndSetter("name")
+	_, v, _ := vGetter(7)
+	_, err = cas(7, v, "seven")
+	as.Nil(err)
+	_, err = cas(7, v, "siete")
+	as.Error(err)
+
+	find, err := tbl.Finder([]table.ColumnName{"age"})
+	as.Nil(err)
+	found, _, err := find(2)
+	as.Nil(err)
+	sort.Ints(found)
+	as.Equal([]int{2, 12, 22, 32, 52, 62, 72, 82, 92}, found)
+
+	scan, err := tbl.Scanner("age")
+	as.Nil(err)
+	total := 0
+	as.Nil(scan(func(_ int, row []any) bool {
+		total += row[0].(int)
+		return true
+	}))
+	as.Equal(450-2, total)
+}
+
+func TestBadSharded(t *testing.T) {
+	as := assert.New(t)
+
+	tbl := makeShardedTable(t)
+	getter, err := tbl.Getter("missing")
+	as.Nil(getter)
+	as.EqualError(err, fmt.Sprintf(table.ErrColumnNotFound, "missing"))
+	scan, err := tbl.Scanner("missing")
+	as.Nil(scan)
+	as.EqualError(err, fmt.Sprintf(table.ErrColumnNotFound, "missing"))
+
+	tbl, err = internal.MakeWith[int, any](
+		[]table.ColumnName{"name"},
+		config.Shards(4),
+		config.ChangelogFile(t.TempDir()+"/changelog"),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, config.ErrShardedPersistence)
+
+	ordered, err := internal.MakeOrdered[int, any](
+		[]table.ColumnName{"name"}, config.Shards(4),
+	)
+	as.Nil(ordered)
+	as.EqualError(err, config.ErrShardedOrdered)
+}
+
+func TestShardedChanges(t *testing.T) {
+	as := assert.New(t)
+
+	tbl := makeShardedTable(t)
+	c := tbl.Changes()
+	setter, _ := tbl.Setter("name")
+	as.Nil(setter(1000, "new"))
+	as.Nil(setter(1, "one"))
+
+	received := map[int]table.Operation{}
+	for i := 0; i < 2; i++ {
+		change := <-c.Receive()
+		received[change.Key] = change.Operation
+	}
+	as.Equal(map[int]table.Operation{
+		1000: table.Inserted,
+		1:    table.Updated,
+	}, received)
+	c.Close()
+	as.Nil(setter(1001, "after close"))
+}
+
+func TestShardedSnapshot(t *testing.T) {
+	as := assert.New(t)
+
+	tbl := makeShardedTable(t)
+	var buf bytes.Buffer
+	as.Nil(tbl.Snapshot(&buf))
+
+	path := t.TempDir() + "/snapshot"
+	as.Nil(os.WriteFile(path, buf.Bytes(), 0o644))
+	restored, err := internal.MakeWith[int, any](
+		[]table.ColumnName{"name", "age"}, config.SnapshotFile(path),
+	)
+	as.Nil(err)
+	as.Equal(100, restored.Len())
+}
+
+func TestShardedTransaction(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.MakeWith[int, int](
+		[]table.ColumnName{"balance"}, config.Shards(8),
+	)
+	setter, _ := tbl.Setter("balance")
+	for i := 0; i < 16; i++ {
+		as.Nil(setter(i, 100))
+	}
+
+	tx := tbl.Begin()
+	again, err := tbl.Join(tx)
+	as.Nil(err)
+	as.Nil(transfer(tx, tx, again, 3, 11, 40))
+
+	getter, _ := tbl.Getter("balance")
+	res, _ := getter(3)
+	as.Equal([]int{60}, res)
+	res, _ = getter(11)
+	as.Equal([]int{140}, res)
+
+	tx = tbl.Begin()
+	as.Nil(tx.Deleter()(3))
+	txGetter, _ := tx.Getter("balance")
+	_, err = txGetter(3)
+	as.EqualError(err, fmt.Sprintf(table.ErrKeyNotFound, 3))
+	tx.Rollback()
+	res, _ = getter(3)
+	as.Equal([]int{60}, res)
+
+	_, err = tbl.Join(tx)
+	as.EqualError(err, table.ErrTransactionFinished)
+	_, err = tbl.Join(fakeTransaction{})
+	as.Error(err)
+}
+
+func TestShardedFloatKeys(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.MakeWith[float64, any](
+		[]table.ColumnName{"name"}, config.Shards(16),
+	)
+	setter, _ := tbl.Setter("name")
+	getter, _ := tbl.Getter("name")
+	as.Nil(setter(0, "zero"))
+	res, err := getter(math.Copysign(0, -1))
+	as.Nil(err)
+	as.Equal([]any{"zero"}, res)
+}
+
+type point struct {
+	x, y float64
+}
+
+func TestShardedCompositeKeys(t *testing.T) {
+	as := assert.New(t)
+
+	structs, _ := internal.MakeWith[point, any](
+		[]table.ColumnName{"name"}, config.Shards(16),
+	)
+	setter, _ := structs.Setter("name")
+	getter, _ := structs.Getter("name")
+	as.Nil(setter(point{0, 1}, "origin"))
+	res, err := getter(point{math.Copysign(0, -1), 1})
+	as.Nil(err)
+	as.Equal([]any{"origin"}, res)
+
+	// Pointers are equal by address, so mutating the pointee must not move
+	// the Key to another shard
+	pointers, _ := internal.MakeWith[*point, any](
+		[]table.ColumnName{"name"}, config.Shards(16),
+	)
+	pSetter, _ := pointers.Setter("name")
+	pGetter, _ := pointers.Getter("name")
+	keys := make([]*point, 64)
+	for i := range keys {
+		keys[i] = &point{float64(i), 0}
+		as.Nil(pSetter(keys[i], i))
+	}
+	for i, k := range keys {
+		k.x, k.y = -1, float64(i*7)
+		res, err := pGetter(k)
+		as.Nil(err)
+		as.Equal([]any{i}, res)
+	}
+	_, err = pGetter(&point{-1, 0})
+	as.NotNil(err)
+}
+
+func benchmarkTable(b *testing.B, o ...config.Option) {
+	tbl, _ := internal.MakeWith[int, any](
+		[]table.ColumnName{"name", "age"}, o...,
+	)
+	setter, _ := tbl.Setter("name", "age")
+	getter, _ := tbl.Getter("age")
+	for i := 0; i < 1024; i++ {
+		_ = setter(i, "name", i)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			k := i % 1024
+			if i%4 == 0 {
+				_ = setter(k, "name", i)
+			} else {
+				_, _ = getter(k)
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkTable(b *testing.B) {
+	benchmarkTable(b)
+}
+
+func BenchmarkShardedTable(b *testing.B) {
+	benchmarkTable(b, config.Shards(32))
+}
+
+func TestShardedMultiGetter(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.MakeWith[int, any](
+		[]table.ColumnName{"name"}, config.Shards(4),
+	)
+	setter, _ := tbl.Setter("name")
+	for i := 0; i < 10; i += 2 {
+		as.Nil(setter(i, fmt.Sprint(i)))
+	}
+
+	getter, _ := tbl.MultiGetter("name")
+	rows, missing, err := getter(9, 8, 7, 6, 5, 4, 3, 2, 1, 0)
+	as.Nil(err)
+	as.Equal(map[int][]any{
+		0: {"0"}, 2: {"2"}, 4: {"4"}, 6: {"6"}, 8: {"8"},
+	}, rows)
+	as.Equal([]int{9, 7, 5, 3, 1}, missing)
+	as.Equal(table.Lookups{Hits: 5, Misses: 5}, stats(t, tbl).Lookups)
+}
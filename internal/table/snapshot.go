@@ -0,0 +1,237 @@
+package table
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/config"
+)
+
+type (
+	// persistence tracks the automatic snapshotting of a Table to its
+	// snapshot file
+	persistence struct {
+		sync.Mutex // serializes writes to the file
+		path       string
+		interval   time.Duration
+		scheduled  bool
+		timer      *time.Timer
+	}
+
+	snapshotHeader struct {
+		Columns []table.ColumnName
+		Rows    int
+	}
+
+	// snapshotRow is a row of a snapshot. Unset holds the positions of the
+	// columns that have never been set, in the order of the header's Columns
+	snapshotRow[Key comparable, Value any] struct {
+		Key    Key
+		Values []Value
+		Unset  []int `json:",omitempty"`
+	}
+)
+
+func makePersistence(cfg *config.Config) (*persistence, error) {
+	if cfg.SnapshotFile == "" {
+		if cfg.SnapshotInterval != 0 {
+			return nil, errors.New(config.ErrSnapshotFileRequired)
+		}
+		return nil, nil
+	}
+	return &persistence{
+		path:     cfg.SnapshotFile,
+		interval: cfg.SnapshotInterval,
+	}, nil
+}
+
+func (t *Table[Key, Value]) Snapshot(w io.Writer) error {
+	names, rows, err := t.selectAll()
+	if err != nil {
+		return err
+	}
+	return t.writeSnapshot(w, names, rows)
+}
+
+func (t *Table[Key, Value]) writeSnapshot(
+	w io.Writer, names []table.ColumnName, rows []*selected[Key, Value],
+) error {
+	enc := t.codec.NewEncoder(w)
+	if err := enc.Encode(&snapshotHeader{
+		Columns: names,
+		Rows:    len(rows),
+	}); err != nil {
+		return err
+	}
+	for _, r := range rows {
+		if err := enc.Encode(&snapshotRow[Key, Value]{
+			Key:    r.key,
+			Values: r.values,
+			Unset:  r.unset.positions(),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restore loads the rows of a snapshot into the Table. Snapshot columns are
+// matched to the Table's columns by name, so their order may differ. Columns
+// that the snapshot doesn't have are unset in every row, and hold their
+// default
+func (t *Table[Key, Value]) restore(r io.Reader) error {
+	dec := t.codec.NewDecoder(r)
+	var h snapshotHeader
+	if err := dec.Decode(&h); err != nil {
+		return err
+	}
+
+	t.Lock()
+	defer t.Unlock()
+
+	indexes, err := t.columnIndexes(h.Columns)
+	if err != nil {
+		return err
+	}
+
+	missing := make([]bool, len(t.names))
+	for i := range missing {
+		missing[i] = true
+	}
+	for _, out := range indexes {
+		missing[out] = false
+	}
+
+	now := time.Now()
+	for i := 0; i < h.Rows; i++ {
+		var sr snapshotRow[Key, Value]
+		if err := dec.Decode(&sr); err != nil {
+			return err
+		}
+		if len(sr.Values) != len(indexes) {
+			return fmt.Errorf(
+				table.ErrValueCountRequired, len(indexes), len(sr.Values),
+			)
+		}
+		// Columns that the snapshot doesn't have hold their defaults
+		row := make([]Value, len(t.names))
+		copy(row, t.defaults)
+		for in, out := range indexes {
+			row[out] = sr.Values[in]
+		}
+		var unset []int
+		for out, m := range missing {
+			if m {
+				unset = append(unset, out)
+			}
+		}
+		for _, in := range sr.Unset {
+			if in >= 0 && in < len(indexes) {
+				unset = append(unset, indexes[in])
+			}
+		}
+		u := unsetAt(unset, len(t.names))
+		if err := t.loadRow(sr.Key, row, u, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *Table[_, _]) restoreFile() error {
+	if t.persist == nil {
+		return nil
+	}
+	f, err := os.Open(t.persist.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	return t.restore(bufio.NewReader(f))
+}
+
+// markDirty schedules an automatic snapshot if one isn't already pending. The
+// caller must hold the write lock
+func (t *Table[_, _]) markDirty() {
+	p := t.persist
+	if p == nil || p.interval == 0 || p.scheduled || t.closed {
+		return
+	}
+	p.scheduled = true
+	p.timer = time.AfterFunc(p.interval, t.autoSnapshot)
+}
+
+// stopSnapshots stops a pending automatic snapshot from being taken, leaving
+// it scheduled so that closing the Table takes it instead. The caller must
+// hold the write lock
+func (t *Table[_, _]) stopSnapshots() {
+	if p := t.persist; p != nil && p.timer != nil {
+		p.timer.Stop()
+	}
+}
+
+// isSnapshotPending reports whether there are writes that haven't been
+// captured by an automatic snapshot
+func (t *Table[_, _]) isSnapshotPending() bool {
+	t.RLock()
+	defer t.RUnlock()
+	return t.persist.scheduled
+}
+
+func (t *Table[_, _]) autoSnapshot() {
+	p := t.persist
+	p.Lock()
+	defer p.Unlock()
+
+	t.Lock()
+	if t.closed {
+		// Closing the Table takes the snapshot instead
+		t.Unlock()
+		return
+	}
+	p.scheduled = false
+	t.Unlock()
+
+	if err := t.snapshotFile(); err != nil {
+		t.Lock()
+		t.fail(err)
+		t.Unlock()
+	}
+}
+
+// snapshotFile replaces the Table's snapshot file. The snapshot is written to
+// a temporary file first, so that a failure never clobbers the previous one.
+// The caller must hold the persistence lock
+func (t *Table[_, _]) snapshotFile() error {
+	p := t.persist
+	tmp := p.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	err = t.Snapshot(w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cErr := f.Close(); err == nil {
+		err = cErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, p.path)
+}
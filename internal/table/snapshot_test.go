@@ -0,0 +1,161 @@
+package table_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/codec"
+	"github.com/caravan/streaming/table/config"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/streaming/internal/table"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	as := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "table.snapshot")
+	tbl, _ := internal.Make[string, any]("name", "age")
+	setter, _ := tbl.Setter("name", "age")
+	as.Nil(setter("1", "bob", 42))
+	as.Nil(setter("2", "june", 36))
+
+	var buf bytes.Buffer
+	as.Nil(tbl.Snapshot(&buf))
+	as.Nil(os.WriteFile(path, buf.Bytes(), 0o644))
+
+	// Columns are matched by name
+	restored, err := internal.MakeWith[string, any](
+		[]table.ColumnName{"age", "name"},
+		config.SnapshotFile(path),
+	)
+	as.NotNil(restored)
+	as.Nil(err)
+
+	getter, _ := restored.Getter("name", "age")
+	res, _ := getter("1")
+	as.Equal([]any{"bob", 42}, res)
+	res, _ = getter("2")
+	as.Equal([]any{"june", 36}, res)
+
+	// Columns missing from the snapshot hold their defaults
+	restored, err = internal.MakeWith[string, any](
+		[]table.ColumnName{"name", "age", "city"},
+		config.SnapshotFile(path),
+		config.ColumnDefault("city", "unknown"),
+	)
+	as.Nil(err)
+	presence, _ := restored.PresenceGetter("name", "city")
+	res, set, _ := presence("1")
+	as.Equal([]any{"bob", "unknown"}, res)
+	as.Equal([]bool{true, false}, set)
+
+	// Columns that don't exist can't be restored
+	restored, err = internal.MakeWith[string, any](
+		[]table.ColumnName{"name"},
+		config.SnapshotFile(path),
+	)
+	as.Nil(restored)
+	as.EqualError(err, fmt.Sprintf(table.ErrColumnNotFound, "age"))
+}
+
+func TestSnapshotMissingFile(t *testing.T) {
+	as := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "missing.snapshot")
+	tbl, err := internal.MakeWith[string, any](
+		[]table.ColumnName{"name"},
+		config.SnapshotFile(path),
+	)
+	as.NotNil(tbl)
+	as.Nil(err)
+
+	tbl, err = internal.MakeWith[string, any](
+		[]table.ColumnName{"name"},
+		config.SnapshotInterval(time.Second),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, config.ErrSnapshotFileRequired)
+}
+
+func TestSnapshotCorruptFile(t *testing.T) {
+	as := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "corrupt.snapshot")
+	as.Nil(os.WriteFile(path, []byte("not a snapshot"), 0o644))
+	tbl, err := internal.MakeWith[string, any](
+		[]table.ColumnName{"name"},
+		config.SnapshotFile(path),
+	)
+	as.Nil(tbl)
+	as.NotNil(err)
+}
+
+func TestAutomaticSnapshot(t *testing.T) {
+	as := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "table.snapshot")
+	cols := []table.ColumnName{"name", "age"}
+	tbl, _ := internal.MakeWith[string, string](cols,
+		config.SnapshotFile(path),
+		config.SnapshotInterval(10*time.Millisecond),
+		config.Codec(codec.JSON),
+	)
+	setter, _ := tbl.Setter("name", "age")
+	as.Nil(setter("1", "bob", "42"))
+	as.Nil(setter("2", "june", "36"))
+	as.Nil(tbl.Deleter()("1"))
+
+	time.Sleep(50 * time.Millisecond)
+	_, err := os.Stat(path + ".tmp")
+	as.True(os.IsNotExist(err))
+
+	restored, err := internal.MakeWith[string, string](cols,
+		config.SnapshotFile(path),
+		config.Codec(codec.JSON),
+	)
+	as.Nil(err)
+	getter, _ := restored.Getter("name", "age")
+	_, err = getter("1")
+	as.EqualError(err, fmt.Sprintf(table.ErrKeyNotFound, "1"))
+	res, _ := getter("2")
+	as.Equal([]string{"june", "36"}, res)
+}
+
+func TestCloseSnapshot(t *testing.T) {
+	as := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "table.snapshot")
+	cols := []table.ColumnName{"name"}
+	open := func() table.Table[string, string] {
+		tbl, err := internal.MakeWith[string, string](cols,
+			config.SnapshotFile(path),
+			config.SnapshotInterval(time.Hour),
+		)
+		as.Nil(err)
+		return tbl
+	}
+
+	tbl := open()
+	setter, _ := tbl.Setter("name")
+	as.Nil(setter("1", "bob"))
+	_, err := os.Stat(path)
+	as.True(os.IsNotExist(err))
+
+	// Closing takes the snapshot that was waiting on the interval
+	as.Nil(tbl.Close())
+	as.Nil(tbl.Close())
+	restored := open()
+	as.Equal(1, restored.Len())
+
+	// Nothing has been written, so there's nothing to snapshot
+	before, _ := os.Stat(path)
+	as.Nil(restored.Close())
+	after, _ := os.Stat(path)
+	as.Equal(before.ModTime(), after.ModTime())
+}
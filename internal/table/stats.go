@@ -0,0 +1,113 @@
+package table
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/store"
+)
+
+type (
+	// lock is a sync.RWMutex that measures how long its callers wait to
+	// acquire it. An uncontended lock is acquired without being timed
+	lock struct {
+		sync.RWMutex
+		waited atomic.Int64
+	}
+
+	// usage counts the reads and writes of a column
+	usage struct {
+		reads  atomic.Uint64
+		writes atomic.Uint64
+	}
+)
+
+func (l *lock) Lock() {
+	if l.TryLock() {
+		return
+	}
+	start := time.Now()
+	l.RWMutex.Lock()
+	l.waited.Add(int64(time.Since(start)))
+}
+
+func (l *lock) RLock() {
+	if l.TryRLock() {
+		return
+	}
+	start := time.Now()
+	l.RWMutex.RLock()
+	l.waited.Add(int64(time.Since(start)))
+}
+
+func makeUsage(n int) []*usage {
+	res := make([]*usage, n)
+	for i := range res {
+		res[i] = &usage{}
+	}
+	return res
+}
+
+// countReads counts a read of each of the columns at the provided positions
+// for every one of the rows. The caller must hold at least the read lock
+func (t *Table[_, _]) countReads(indexes []int, rows int) {
+	if rows == 0 {
+		return
+	}
+	for _, i := range indexes {
+		t.usage[i].reads.Add(uint64(rows))
+	}
+}
+
+// countWrites counts a write of each of the columns at the provided
+// positions. The caller must hold at least the read lock
+func (t *Table[_, _]) countWrites(indexes []int) {
+	for _, i := range indexes {
+		t.usage[i].writes.Add(1)
+	}
+}
+
+func (t *Table[Key, Value]) Stats() (table.Stats, error) {
+	t.RLock()
+	defer t.RUnlock()
+
+	res := table.Stats{
+		Rows:    t.count(time.Now()),
+		Columns: make(map[table.ColumnName]table.ColumnStats, len(t.names)),
+		Lookups: table.Lookups{
+			Hits:   t.hits.Load(),
+			Misses: t.misses.Load(),
+		},
+		LockWait: time.Duration(t.waited.Load()),
+	}
+	for i, n := range t.names {
+		res.Columns[n] = table.ColumnStats{
+			Reads:  t.usage[i].reads.Load(),
+			Writes: t.usage[i].writes.Load(),
+		}
+	}
+	if s, ok := t.rows.(store.Sized); ok {
+		res.Bytes = s.Bytes()
+	} else {
+		res.Bytes = t.size
+	}
+	return res, nil
+}
+
+// resize adjusts the running estimate of the memory taken up by the Table's
+// rows when a row is added, removed, or replaced, so that Stats never has to
+// visit every row. The caller must hold the write lock
+func (t *Table[Key, Value]) resize(added, removed int64) {
+	t.size += added - removed
+}
+
+// sizeOf estimates the memory taken up by a row, or returns zero if there's
+// no row or the Store keeps track of its own size
+func (t *Table[Key, Value]) sizeOf(k Key, row []Value) int64 {
+	if _, ok := t.rows.(store.Sized); ok || row == nil {
+		return 0
+	}
+	return store.SizeOf(k, row)
+}
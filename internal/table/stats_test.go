@@ -0,0 +1,168 @@
+package table_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/config"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/streaming/internal/table"
+)
+
+func stats[Key comparable, Value any](
+	t *testing.T, tbl table.Table[Key, Value],
+) table.Stats {
+	res, err := tbl.Stats()
+	assert.Nil(t, err)
+	return res
+}
+
+func TestStats(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.Make[string, any]("name", "age")
+	setter, _ := tbl.Setter("name", "age")
+	nameGetter, _ := tbl.Getter("name")
+	scanner, _ := tbl.Scanner("age")
+
+	as.Equal(table.Stats{
+		Columns: map[table.ColumnName]table.ColumnStats{
+			"name": {},
+			"age":  {},
+		},
+	}, stats(t, tbl))
+
+	as.Nil(setter("1", "bill", 42))
+	as.Nil(setter("2", "jane", 37))
+	_, err := nameGetter("1")
+	as.Nil(err)
+	_, err = nameGetter("3")
+	as.NotNil(err)
+	as.Nil(scanner(func(string, []any) bool { return true }))
+
+	s := stats(t, tbl)
+	as.Equal(2, s.Rows)
+	as.Greater(s.Bytes, int64(0))
+	as.Equal(map[table.ColumnName]table.ColumnStats{
+		"name": {Reads: 1, Writes: 2},
+		"age":  {Reads: 2, Writes: 2},
+	}, s.Columns)
+	as.Equal(table.Lookups{Hits: 1, Misses: 1}, s.Lookups)
+	as.Equal(0.5, s.Lookups.MissRate())
+
+	// Writes made by a Transaction only count once it's committed
+	tx := tbl.Begin()
+	txSetter, _ := tx.Setter("age")
+	as.Nil(txSetter("1", 43))
+	tx.Rollback()
+	as.Equal(uint64(2), stats(t, tbl).Columns["age"].Writes)
+
+	tx = tbl.Begin()
+	txSetter, _ = tx.Setter("age")
+	as.Nil(txSetter("1", 43))
+	as.Nil(txSetter("2", 38))
+	as.Nil(tx.Commit())
+	as.Equal(uint64(4), stats(t, tbl).Columns["age"].Writes)
+
+	// Counts follow their columns through schema changes
+	as.Nil(tbl.DropColumn("name"))
+	as.Nil(tbl.AddColumn("tier", "gold"))
+	as.Equal(map[table.ColumnName]table.ColumnStats{
+		"age":  {Reads: 2, Writes: 4},
+		"tier": {},
+	}, stats(t, tbl).Columns)
+}
+
+func TestStatsLockWait(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.Make[string, any]("name")
+	getter, _ := tbl.Getter("name")
+	as.Equal(time.Duration(0), stats(t, tbl).LockWait)
+
+	// A Transaction holds the write lock until it's committed
+	tx := tbl.Begin()
+	done := make(chan struct{})
+	go func() {
+		_, _ = getter("1")
+		close(done)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	as.Nil(tx.Commit())
+	<-done
+	as.GreaterOrEqual(stats(t, tbl).LockWait, 20*time.Millisecond)
+}
+
+func TestStatsBytes(t *testing.T) {
+	as := assert.New(t)
+
+	columns := []table.ColumnName{"name"}
+	plain, _ := internal.Make[string, any](columns...)
+	bounded, _ := internal.MakeWith[string, any](columns, config.MaxRows(10))
+	tables := []table.Table[string, any]{plain, bounded}
+	for _, tbl := range tables {
+		setter, _ := tbl.Setter("name")
+		as.Nil(setter("1", "bill"))
+	}
+	as.Greater(stats(t, plain).Bytes, int64(0))
+	as.Equal(stats(t, plain).Bytes, stats(t, bounded).Bytes)
+
+	// The estimate follows rows as they're replaced, reshaped, and removed
+	for _, tbl := range tables {
+		setter, _ := tbl.Setter("name")
+		as.Nil(setter("1", "a much longer name"))
+		as.Nil(setter("2", "jane"))
+		as.Nil(tbl.AddColumn("age", 42))
+		as.Nil(tbl.Deleter()("2"))
+	}
+	as.Equal(stats(t, plain).Bytes, stats(t, bounded).Bytes)
+	for _, tbl := range tables {
+		as.Nil(tbl.Deleter()("1"))
+	}
+	as.Zero(stats(t, plain).Bytes)
+	as.Zero(stats(t, bounded).Bytes)
+}
+
+func TestStatsLookups(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.Make[string, any]("name")
+	setter, _ := tbl.Setter("name")
+	as.Nil(setter("1", "bill"))
+
+	versioned, _ := tbl.VersionedGetter("name")
+	presence, _ := tbl.PresenceGetter("name")
+	_, _, _ = versioned("1")
+	_, _, _ = versioned("2")
+	_, _, _ = presence("1")
+	_, _, _ = presence("2")
+	as.Equal(table.Lookups{Hits: 2, Misses: 2}, stats(t, tbl).Lookups)
+}
+
+func TestShardedStats(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.MakeWith[int, any](
+		[]table.ColumnName{"name"}, config.Shards(4),
+	)
+	setter, _ := tbl.Setter("name")
+	getter, _ := tbl.Getter("name")
+	scanner, _ := tbl.Scanner("name")
+	for i := 0; i < 10; i++ {
+		as.Nil(setter(i, i))
+	}
+	for i := 0; i < 10; i++ {
+		_, _ = getter(i * 2)
+	}
+	as.Nil(scanner(func(int, []any) bool { return true }))
+
+	s := stats(t, tbl)
+	as.Equal(10, s.Rows)
+	as.Greater(s.Bytes, int64(0))
+	as.Equal(table.Lookups{Hits: 5, Misses: 5}, s.Lookups)
+	as.Equal(map[table.ColumnName]table.ColumnStats{
+		"name": {Reads: 15, Writes: 10},
+	}, s.Columns)
+}
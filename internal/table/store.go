@@ -0,0 +1,147 @@
+package table
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/config"
+	"github.com/caravan/streaming/table/store"
+)
+
+// makeStore creates the Store for a shard of a Table using the configured
+// Factory, a bounded Store if the Table has bounds, or an in-memory map if
+// there's neither
+func makeStore[Key comparable, Value any](
+	cfg *config.Config, shard int,
+) (store.Store[Key, Value], error) {
+	if isBounded(cfg) {
+		return store.NewBounded[Key, Value](shardLimits(cfg), cfg.Eviction)
+	}
+	if cfg.Store == nil {
+		return store.NewMap[Key, Value](), nil
+	}
+	f, ok := cfg.Store.(store.Factory[Key, Value])
+	if !ok {
+		return nil, fmt.Errorf(config.ErrStoreType, cfg.Store)
+	}
+	return f(shard)
+}
+
+// checkBounds makes sure that an eviction policy comes with bounds, and that
+// the bounds aren't combined with a custom Store
+func checkBounds(cfg *config.Config) error {
+	if cfg.Eviction != 0 && !isBounded(cfg) {
+		return errors.New(config.ErrBoundRequired)
+	}
+	if isBounded(cfg) && cfg.Store != nil {
+		return errors.New(config.ErrBoundedStore)
+	}
+	return nil
+}
+
+func isBounded(cfg *config.Config) bool {
+	return cfg.MaxRows != 0 || cfg.MaxMemory != 0
+}
+
+// shardLimits divides a Table's bounds between its shards, rounding up
+func shardLimits(cfg *config.Config) store.Limits {
+	shards := cfg.Shards
+	if shards < 1 {
+		shards = 1
+	}
+	return store.Limits{
+		Rows:  (cfg.MaxRows + shards - 1) / shards,
+		Bytes: (cfg.MaxMemory + int64(shards) - 1) / int64(shards),
+	}
+}
+
+func makeEvictHandler[Key comparable, Value any](
+	cfg *config.Config,
+) (config.EvictHandler[Key, Value], error) {
+	if cfg.OnEvict == nil {
+		return nil, nil
+	}
+	h, ok := cfg.OnEvict.(config.EvictHandler[Key, Value])
+	if !ok {
+		return nil, fmt.Errorf(config.ErrEvictHandlerType, cfg.OnEvict)
+	}
+	return h, nil
+}
+
+// loadStore tracks the rows that are already in the Table's Store, such as
+// those kept on disk, and registers to hear about the rows it evicts
+func (t *Table[Key, Value]) loadStore() error {
+	t.Lock()
+	defer t.Unlock()
+
+	now := time.Now()
+	var err error
+	eachErr := t.rows.Each(func(k Key, row []Value) bool {
+		if len(row) != len(t.names) {
+			err = fmt.Errorf(
+				table.ErrValueCountRequired, len(t.names), len(row),
+			)
+			return false
+		}
+		var entries []any
+		if entries, err = t.indexEntries(row); err != nil {
+			return false
+		}
+		t.track(k, entries, now)
+		t.resize(t.sizeOf(k, row), 0)
+		return true
+	})
+	if err == nil {
+		err = eachErr
+	}
+	if err != nil {
+		return err
+	}
+	if e, ok := t.rows.(store.Evicting[Key, Value]); ok {
+		e.OnEvict(t.evicted)
+	} else if t.onEvict != nil {
+		return errors.New(config.ErrEvictingStore)
+	}
+	return nil
+}
+
+// peek reads the row of the Key for the Table's own use, such as before it's
+// written, so that the read isn't counted as a use by a Peeking Store
+func (t *Table[Key, Value]) peek(k Key) ([]Value, bool, error) {
+	if p, ok := t.rows.(store.Peeking[Key, Value]); ok {
+		return p.Peek(k)
+	}
+	return t.rows.Get(k)
+}
+
+// evicted is called by an Evicting Store for each row that it evicts. It's
+// called during a Put, so the write lock is already held. The row is held
+// until whoever made the Put can notify the evict handler without the lock
+func (t *Table[Key, Value]) evicted(k Key, row []Value) {
+	if t.isWatched() {
+		// The Put that triggered the eviction doesn't fail on its account,
+		// so a failure is reported when the Table is closed
+		t.fail(t.record(&table.Change[Key, Value]{
+			Operation: table.Evicted,
+			Key:       k,
+			Old:       row,
+		}, nil))
+	}
+	t.untrack(k, row)
+	t.markDirty()
+	t.evictions = append(t.evictions, &removal[Key, Value]{
+		op:  table.Evicted,
+		key: k,
+		row: row,
+	})
+}
+
+// takeEvictions returns the rows that have been evicted since it was last
+// called. The caller must hold the write lock
+func (t *Table[Key, Value]) takeEvictions() []*removal[Key, Value] {
+	res := t.evictions
+	t.evictions = nil
+	return res
+}
@@ -0,0 +1,273 @@
+package table_test
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/codec"
+	"github.com/caravan/streaming/table/config"
+	"github.com/caravan/streaming/table/store"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/streaming/internal/table"
+)
+
+func TestLRUStore(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := internal.MakeWith[string, any](
+		[]table.ColumnName{"name"},
+		config.Store(store.LRU[string, any](2)),
+		config.Index("name"),
+	)
+	as.Nil(err)
+	c := tbl.Changes()
+	defer c.Close()
+
+	setter, _ := tbl.Setter("name")
+	getter, _ := tbl.Getter("name")
+	as.Nil(setter("1", "bob"))
+	as.Nil(setter("2", "june"))
+	_, _ = getter("1")
+	as.Nil(setter("3", "bob"))
+	as.Equal(2, tbl.Len())
+
+	_, err = getter("2")
+	as.EqualError(err, fmt.Sprintf(table.ErrKeyNotFound, "2"))
+
+	// A row is evicted by the insert that needs room for it
+	as.Equal(table.Inserted, (<-c.Receive()).Operation)
+	as.Equal(table.Inserted, (<-c.Receive()).Operation)
+	as.Equal(table.Inserted, (<-c.Receive()).Operation)
+	as.Equal(&table.Change[string, any]{
+		Operation: table.Evicted,
+		Key:       "2",
+		Old:       []any{"june"},
+	}, <-c.Receive())
+	as.Equal("evicted", table.Evicted.String())
+
+	// Evicted rows no longer appear in indexes or versions
+	find, _ := tbl.Finder([]table.ColumnName{"name"})
+	keys, _, _ := find("june")
+	as.Empty(keys)
+	keys, _, _ = find("bob")
+	sort.Strings(keys)
+	as.Equal([]string{"1", "3"}, keys)
+	cas, _ := tbl.CompareAndSetter("name")
+	_, err = cas("2", 0, "june")
+	as.Nil(err)
+}
+
+func TestDiskStore(t *testing.T) {
+	as := assert.New(t)
+
+	dir := t.TempDir()
+	makeTable := func() table.Table[string, any] {
+		tbl, err := internal.MakeWith[string, any](
+			[]table.ColumnName{"name", "age"},
+			config.Store(store.Disk[string, any](dir, codec.Gob)),
+			config.Index("name"),
+		)
+		as.Nil(err)
+		return tbl
+	}
+
+	tbl := makeTable()
+	setter, _ := tbl.Setter("name", "age")
+	as.Nil(setter("1", "bob", 42))
+	as.Nil(setter("2", "june", 36))
+	as.Nil(setter("3", "carol", 47))
+	as.Nil(tbl.Deleter()("3"))
+
+	// Rows are on disk, and the Table's indexes are rebuilt from them
+	tbl = makeTable()
+	as.Equal(2, tbl.Len())
+	getter, _ := tbl.Getter("age")
+	res, err := getter("2")
+	as.Nil(err)
+	as.Equal([]any{36}, res)
+	find, _ := tbl.Finder([]table.ColumnName{"name"}, "age")
+	keys, rows, _ := find("bob")
+	as.Equal([]string{"1"}, keys)
+	as.Equal([][]any{{42}}, rows)
+}
+
+func TestShardedDiskStore(t *testing.T) {
+	as := assert.New(t)
+
+	dir := t.TempDir()
+	tbl, err := internal.MakeWith[int, any](
+		[]table.ColumnName{"name"},
+		config.Store(store.Disk[int, any](dir, codec.Gob)),
+		config.Shards(4),
+	)
+	as.Nil(err)
+	setter, _ := tbl.Setter("name")
+	for i := 0; i < 20; i++ {
+		as.Nil(setter(i, fmt.Sprint(i)))
+	}
+
+	tbl, _ = internal.MakeWith[int, any](
+		[]table.ColumnName{"name"},
+		config.Store(store.Disk[int, any](dir, codec.Gob)),
+		config.Shards(4),
+	)
+	as.Equal(20, tbl.Len())
+}
+
+func TestBadStore(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := internal.MakeWith[string, any](
+		[]table.ColumnName{"name"},
+		config.Store(store.Map[string, string]()),
+	)
+	as.Nil(tbl)
+	as.EqualError(err,
+		fmt.Sprintf(config.ErrStoreType, store.Map[string, string]()),
+	)
+
+	tbl, err = internal.MakeWith[string, any](
+		[]table.ColumnName{"name"},
+		config.Store(store.LRU[string, any](0)),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, fmt.Sprintf(store.ErrInvalidCapacity, 0))
+
+	ordered, err := internal.MakeOrdered[string, any](
+		[]table.ColumnName{"name"},
+		config.Store(store.Map[string, any]()),
+	)
+	as.Nil(ordered)
+	as.EqualError(err, config.ErrOrderedStore)
+
+	tbl, err = internal.MakeWith[string, any](
+		[]table.ColumnName{"name"},
+		config.Store(store.Map[string, any]()),
+		config.Store(store.Map[string, any]()),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, config.ErrStoreAlreadySet)
+}
+
+// failingStore is a map Store that fails every call that can report an error
+// once it's told to, and that refuses to Put the rejected Key
+type failingStore struct {
+	store.Store[string, any]
+	failing  atomic.Bool
+	rejected string
+}
+
+var errStoreFailed = errors.New("store failed")
+
+func failing(s *failingStore) store.Factory[string, any] {
+	s.Store = store.NewMap[string, any]()
+	return func(int) (store.Store[string, any], error) {
+		return s, nil
+	}
+}
+
+func (s *failingStore) Get(k string) ([]any, bool, error) {
+	if s.failing.Load() {
+		return nil, false, errStoreFailed
+	}
+	return s.Store.Get(k)
+}
+
+func (s *failingStore) Put(k string, row []any) error {
+	if s.failing.Load() || k == s.rejected {
+		return errStoreFailed
+	}
+	return s.Store.Put(k, row)
+}
+
+func (s *failingStore) Remove(k string) error {
+	if s.failing.Load() {
+		return errStoreFailed
+	}
+	return s.Store.Remove(k)
+}
+
+func (s *failingStore) Each(fn func(string, []any) bool) error {
+	if s.failing.Load() {
+		return errStoreFailed
+	}
+	return s.Store.Each(fn)
+}
+
+func TestStoreFailure(t *testing.T) {
+	as := assert.New(t)
+
+	s := &failingStore{}
+	tbl, err := internal.MakeWith[string, any](
+		[]table.ColumnName{"name"},
+		config.Store(failing(s)),
+	)
+	as.Nil(err)
+	setter, _ := tbl.Setter("name")
+	as.Nil(setter("1", "bob"))
+	scan, _ := tbl.Scanner("name")
+	s.failing.Store(true)
+
+	keys, err := tbl.Keys()
+	as.Nil(keys)
+	as.Equal(errStoreFailed, err)
+	st, err := tbl.Stats()
+	as.Nil(err) // the Store isn't visited
+	as.Greater(st.Bytes, int64(0))
+	visited := false
+	as.Equal(errStoreFailed, scan(func(string, []any) bool {
+		visited = true
+		return true
+	}))
+	as.False(visited)
+}
+
+func TestExpiryStoreFailure(t *testing.T) {
+	as := assert.New(t)
+
+	s := &failingStore{}
+	tbl, err := internal.MakeWith[string, any](
+		[]table.ColumnName{"name"},
+		config.Store(failing(s)),
+		config.TTL(10*time.Millisecond),
+		config.SweepInterval(time.Hour),
+	)
+	as.Nil(err)
+	setter, _ := tbl.Setter("name")
+	as.Nil(setter("1", "bob"))
+	time.Sleep(20 * time.Millisecond)
+
+	// The row expires regardless, but the write that found it reports why
+	// it couldn't be removed cleanly
+	s.failing.Store(true)
+	as.Equal(errStoreFailed, setter("1", "june"))
+	s.failing.Store(false)
+	as.Nil(setter("1", "june"))
+	as.Nil(tbl.Close())
+}
+
+func TestSweeperStoreFailure(t *testing.T) {
+	as := assert.New(t)
+
+	s := &failingStore{}
+	tbl, err := internal.MakeWith[string, any](
+		[]table.ColumnName{"name"},
+		config.Store(failing(s)),
+		config.TTL(10*time.Millisecond),
+		config.SweepInterval(5*time.Millisecond),
+	)
+	as.Nil(err)
+	setter, _ := tbl.Setter("name")
+	as.Nil(setter("1", "bob"))
+	s.failing.Store(true)
+	time.Sleep(50 * time.Millisecond)
+
+	// The sweeper has nobody to report to, so Close does
+	as.Equal(errStoreFailed, tbl.Close())
+}
@@ -0,0 +1,74 @@
+package table
+
+import (
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/column"
+	"github.com/caravan/streaming/table/config"
+)
+
+// Struct is the internal implementation of a table.StructTable. It stores
+// each field of a Row in its own Column of an untyped Table
+type Struct[Key comparable, Row any] struct {
+	table.Table[Key, any]
+	fields []table.Field[Row, any]
+	get    table.Getter[Key, any]
+	set    table.Setter[Key, any]
+}
+
+// MakeStruct instantiates a new internal Struct Table instance, applying the
+// provided Options to its configuration
+func MakeStruct[Key comparable, Row any](
+	o ...config.Option,
+) (table.StructTable[Key, Row], error) {
+	cols, err := column.Fields[Row]()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]table.ColumnName, len(cols))
+	fields := make([]table.Field[Row, any], len(cols))
+	for i, c := range cols {
+		names[i] = c.Name()
+		fields[i] = c.(table.Field[Row, any])
+	}
+	tbl, err := MakeWith[Key, any](names, o...)
+	if err != nil {
+		return nil, err
+	}
+	get, err := tbl.Getter(names...)
+	if err != nil {
+		return nil, err
+	}
+	set, err := tbl.Setter(names...)
+	if err != nil {
+		return nil, err
+	}
+	return &Struct[Key, Row]{
+		Table:  tbl,
+		fields: fields,
+		get:    get,
+		set:    set,
+	}, nil
+}
+
+func (s *Struct[Key, Row]) Get(k Key) (Row, error) {
+	var res Row
+	values, err := s.get(k)
+	if err != nil {
+		return res, err
+	}
+	for i, f := range s.fields {
+		if err := f.Assign(&res, values[i]); err != nil {
+			var zero Row
+			return zero, err
+		}
+	}
+	return res, nil
+}
+
+func (s *Struct[Key, Row]) Put(k Key, r Row) error {
+	values := make([]any, len(s.fields))
+	for i, f := range s.fields {
+		values[i] = f.Select(r)
+	}
+	return s.set(k, values...)
+}
@@ -0,0 +1,102 @@
+package table_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/column"
+	"github.com/caravan/streaming/table/config"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/streaming/internal/table"
+)
+
+type customer struct {
+	Name  string `table:"name"`
+	Age   int    `table:"age"`
+	Email string `table:"email"`
+	notes string
+}
+
+func TestStruct(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := internal.MakeStruct[string, customer]()
+	as.Nil(err)
+	as.Equal([]table.ColumnName{"name", "age", "email"}, tbl.Columns())
+
+	bob := customer{Name: "bob", Age: 42, Email: "bob@example.com"}
+	as.Nil(tbl.Put("1", bob))
+	res, err := tbl.Get("1")
+	as.Nil(err)
+	as.Equal(bob, res)
+
+	_, err = tbl.Get("2")
+	as.EqualError(err, fmt.Sprintf(table.ErrKeyNotFound, "2"))
+
+	// The untyped Table interface sees the same rows
+	getter, _ := tbl.Getter("age", "name")
+	row, err := getter("1")
+	as.Nil(err)
+	as.Equal([]any{42, "bob"}, row)
+
+	// Fields are typed, and work with column.Make
+	age, _ := column.Field[customer, int]("age")
+	getAge, err := table.GetField[string](tbl, age)
+	as.Nil(err)
+	years, err := getAge("1")
+	as.Nil(err)
+	as.Equal(42, years)
+
+	updater, err := internal.MakeUpdater[customer, string, any](tbl,
+		func(c customer) string {
+			return c.Email
+		},
+		column.Make("age", func(c customer) any {
+			return age.Value(c) + 1
+		}),
+	)
+	as.Nil(err)
+	as.Nil(updater.Update(customer{Email: "1", Age: 42}))
+	years, _ = getAge("1")
+	as.Equal(43, years)
+}
+
+func TestPointerStruct(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := internal.MakeStruct[string, *customer](config.Index("email"))
+	as.Nil(err)
+
+	as.Nil(tbl.Put("1", &customer{Name: "bob", Email: "bob@example.com"}))
+	res, err := tbl.Get("1")
+	as.Nil(err)
+	as.Equal(&customer{Name: "bob", Email: "bob@example.com"}, res)
+
+	find, _ := tbl.Finder([]table.ColumnName{"email"}, "name")
+	keys, rows, err := find("bob@example.com")
+	as.Nil(err)
+	as.Equal([]string{"1"}, keys)
+	as.Equal([][]any{{"bob"}}, rows)
+}
+
+func TestStructErrors(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := internal.MakeStruct[string, int]()
+	as.Nil(tbl)
+	as.EqualError(err, fmt.Sprintf(table.ErrStructRequired, "int"))
+
+	tbl2, _ := internal.MakeStruct[string, customer]()
+	setter, _ := tbl2.Setter("age")
+	as.Nil(setter("1", "forty-two"))
+
+	_, err = tbl2.Get("1")
+	as.EqualError(err, fmt.Sprintf(table.ErrFieldValue, "forty-two", "age"))
+
+	age, _ := column.Field[customer, int]("age")
+	getAge, _ := table.GetField[string](tbl2, age)
+	_, err = getAge("1")
+	as.EqualError(err, fmt.Sprintf(table.ErrFieldValue, "forty-two", "age"))
+}
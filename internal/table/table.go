@@ -2,44 +2,159 @@ package table
 
 import (
 	"fmt"
-	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/codec"
+	"github.com/caravan/streaming/table/config"
+	"github.com/caravan/streaming/table/store"
 )
 
 // Table is the internal implementation of a table.Table
 type Table[Key comparable, Value any] struct {
-	sync.RWMutex
-	names   []table.ColumnName
-	indexes map[table.ColumnName]int
-	rows    map[Key][]Value
+	lock
+	names     []table.ColumnName
+	indexes   map[table.ColumnName]int
+	schema    uint64
+	rows      store.Store[Key, Value]
+	secondary []*index[Key, Value]
+	versions  map[Key]table.Version
+	version   table.Version
+	defaults  []Value
+	merges    []table.Reducer[Value]
+	unset     map[Key]unset
+	expiry    *expiry[Key, Value]
+	onEvict   config.EvictHandler[Key, Value]
+	evictions []*removal[Key, Value]
+	size      int64
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	usage     []*usage
+	watchers  []*watcher[Key, Value]
+	changelog *changelog[Key, Value]
+	codec     codec.Codec
+	persist   *persistence
+	closed    bool
+	failure   error
 }
 
+// Make instantiates a new internal Table instance with default settings
 func Make[Key comparable, Value any](
 	c ...table.ColumnName,
 ) (table.Table[Key, Value], error) {
+	return MakeWith[Key, Value](c)
+}
+
+// MakeWith instantiates a new internal Table instance, applying the provided
+// Options to its configuration
+func MakeWith[Key comparable, Value any](
+	c []table.ColumnName, o ...config.Option,
+) (table.Table[Key, Value], error) {
+	cfg, err := makeConfig(c, o)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Shards > 1 {
+		return makeSharded[Key, Value](c, cfg)
+	}
+	rows, err := makeStore[Key, Value](cfg, 0)
+	if err != nil {
+		return nil, err
+	}
+	res, err := makeTable[Key, Value](c, rows, cfg)
+	if err != nil {
+		_ = rows.Close()
+		return nil, err
+	}
+	return res, nil
+}
+
+func makeConfig(
+	c []table.ColumnName, o []config.Option,
+) (*config.Config, error) {
 	if err := checkColumnDuplicates(c); err != nil {
 		return nil, err
 	}
-	indexes := map[table.ColumnName]int{}
-	for i, n := range c {
-		indexes[n] = i
+	cfg := &config.Config{}
+	withDefaults := append(o, config.Defaults)
+	if err := config.ApplyOptions(cfg, withDefaults...); err != nil {
+		return nil, err
 	}
-	return &Table[Key, Value]{
-		names:   c,
-		indexes: indexes,
-		rows:    map[Key][]Value{},
-	}, nil
+	if err := checkBounds(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func makeTable[Key comparable, Value any](
+	c []table.ColumnName, rows store.Store[Key, Value], cfg *config.Config,
+) (*Table[Key, Value], error) {
+	exp, err := makeExpiry[Key, Value](cfg)
+	if err != nil {
+		return nil, err
+	}
+	onEvict, err := makeEvictHandler[Key, Value](cfg)
+	if err != nil {
+		return nil, err
+	}
+	persist, err := makePersistence(cfg)
+	if err != nil {
+		return nil, err
+	}
+	indexes := columnPositions(c)
+	defaults, err := makeDefaults[Value](cfg, indexes)
+	if err != nil {
+		return nil, err
+	}
+	merges, err := makeMerges[Value](cfg, indexes)
+	if err != nil {
+		return nil, err
+	}
+	res := &Table[Key, Value]{
+		names:    c,
+		indexes:  indexes,
+		rows:     rows,
+		versions: map[Key]table.Version{},
+		defaults: defaults,
+		merges:   merges,
+		unset:    map[Key]unset{},
+		expiry:   exp,
+		onEvict:  onEvict,
+		codec:    cfg.Codec,
+		persist:  persist,
+		usage:    makeUsage(len(c)),
+	}
+	if err := res.makeIndexes(cfg); err != nil {
+		return nil, err
+	}
+	if err := res.loadStore(); err != nil {
+		return nil, err
+	}
+	if err := res.restoreFile(); err != nil {
+		return nil, err
+	}
+	if err := res.openChangelog(cfg); err != nil {
+		return nil, err
+	}
+	// Rows restored beyond the Table's bounds have already been evicted
+	res.Lock()
+	evicted := res.takeEvictions()
+	res.Unlock()
+	res.notifyRemoved(evicted)
+	return res, nil
 }
 
 func (t *Table[_, _]) Columns() []table.ColumnName {
+	t.RLock()
+	defer t.RUnlock()
 	return t.names[:]
 }
 
 func (t *Table[Key, Value]) Getter(
 	c ...table.ColumnName,
 ) (table.Getter[Key, Value], error) {
-	indexes, err := t.columnIndexes(c)
+	sel, err := t.selectColumns(c)
 	if err != nil {
 		return nil, err
 	}
@@ -47,21 +162,68 @@ func (t *Table[Key, Value]) Getter(
 		t.RLock()
 		defer t.RUnlock()
 
-		if e, ok := t.rows[k]; ok {
+		indexes, err := t.positions(sel)
+		if err != nil {
+			return nil, err
+		}
+		e, ok, err := t.rows.Get(k)
+		if err != nil {
+			return nil, err
+		}
+		if ok && !t.isExpired(k, time.Now()) {
+			t.hits.Add(1)
+			t.countReads(indexes, 1)
 			res := make([]Value, len(indexes))
 			for out, in := range indexes {
 				res[out] = e[in]
 			}
 			return res, nil
 		}
+		t.misses.Add(1)
 		return nil, fmt.Errorf(table.ErrKeyNotFound, k)
 	}, nil
 }
 
+func (t *Table[Key, Value]) MultiGetter(
+	c ...table.ColumnName,
+) (table.MultiGetter[Key, Value], error) {
+	sel, err := t.selectColumns(c)
+	if err != nil {
+		return nil, err
+	}
+	return func(keys ...Key) (map[Key][]Value, []Key, error) {
+		t.RLock()
+		defer t.RUnlock()
+
+		indexes, err := t.positions(sel)
+		if err != nil {
+			return nil, nil, err
+		}
+		now := time.Now()
+		res := make(map[Key][]Value, len(keys))
+		var missing []Key
+		for _, k := range keys {
+			e, ok, err := t.rows.Get(k)
+			if err != nil {
+				return nil, nil, err
+			}
+			if ok && !t.isExpired(k, now) {
+				res[k] = selectRow(k, e, indexes).values
+			} else {
+				missing = append(missing, k)
+			}
+		}
+		t.hits.Add(uint64(len(keys) - len(missing)))
+		t.countReads(indexes, len(res))
+		t.misses.Add(uint64(len(missing)))
+		return res, missing, nil
+	}, nil
+}
+
 func (t *Table[Key, Value]) Setter(
 	c ...table.ColumnName,
 ) (table.Setter[Key, Value], error) {
-	indexes, err := t.columnIndexes(c)
+	sel, err := t.selectColumns(c)
 	if err != nil {
 		return nil, err
 	}
@@ -70,24 +232,176 @@ func (t *Table[Key, Value]) Setter(
 	}
 
 	return func(k Key, v ...Value) error {
-		t.Lock()
-		defer t.Unlock()
+		if len(v) != len(c) {
+			return fmt.Errorf(table.ErrValueCountRequired, len(c), len(v))
+		}
+		_, exp, err := t.set(k, sel, v, nil)
+		t.notifyRemoved(exp)
+		return err
+	}, nil
+}
+
+// set writes the Values to the row of the Key. If expected isn't nil, the
+// write only happens if the row's current Version matches it
+func (t *Table[Key, Value]) set(
+	k Key, sel *selection, v []Value, expected *table.Version,
+) (table.Version, []*removal[Key, Value], error) {
+	t.Lock()
+	defer t.Unlock()
 
-		if len(v) != len(indexes) {
-			return fmt.Errorf(
-				table.ErrValueCountRequired, len(indexes), len(v),
-			)
+	indexes, err := t.positions(sel)
+	if err != nil {
+		return 0, nil, err
+	}
+	now := time.Now()
+	exp, err := t.expireIfDue(k, now)
+	if err != nil {
+		return 0, exp, err
+	}
+	if found := t.versions[k]; expected != nil && found != *expected {
+		return 0, exp, &table.VersionConflict{
+			Key:      k,
+			Expected: *expected,
+			Found:    found,
 		}
-		e, ok := t.rows[k]
-		if !ok {
-			e = make([]Value, len(t.names))
+	}
+	old, ok, err := t.peek(k)
+	if err != nil {
+		return 0, exp, err
+	}
+	row := old
+	if !ok || t.isWatched() || len(t.secondary) != 0 || t.merges != nil {
+		// Rows that have been handed out in a Change are never modified,
+		// indexed rows must keep their old Values until unindexed, and a
+		// failed merge mustn't leave a row half written
+		row = make([]Value, len(t.names))
+		copy(row, old)
+	}
+	if !ok {
+		copy(row, t.defaults)
+	}
+	// The old row may be about to be written in place
+	oldSize := t.sizeOf(k, old)
+	if err := t.assign(row, ok, t.unset[k], indexes, v); err != nil {
+		return 0, exp, err
+	}
+	entries, err := t.indexEntries(row)
+	if err != nil {
+		return 0, exp, err
+	}
+
+	u := t.nextUnset(t.unset[k], ok, indexes)
+	if t.isWatched() {
+		c := &table.Change[Key, Value]{
+			Operation: table.Inserted,
+			Key:       k,
+			New:       row,
 		}
-		for in, out := range indexes {
-			e[out] = v[in]
+		if ok {
+			c.Operation = table.Updated
+			c.Old = old
 		}
-		t.rows[k] = e
-		return nil
-	}, nil
+		if err := t.record(c, u); err != nil {
+			return 0, exp, err
+		}
+	}
+	err = t.storeRow(k, old, ok, oldSize, row, entries, now)
+	exp = append(exp, t.takeEvictions()...)
+	if err != nil {
+		return 0, exp, err
+	}
+	t.storeUnset(k, u)
+	t.countWrites(indexes)
+	t.markDirty()
+	return t.versions[k], exp, nil
+}
+
+func (t *Table[Key, _]) Deleter() table.Deleter[Key] {
+	return func(k Key) error {
+		exp, err := t.delete(k)
+		t.notifyRemoved(exp)
+		return err
+	}
+}
+
+// delete removes the row of the Key, if it exists, returning any rows that
+// were removed because they had expired
+func (t *Table[Key, Value]) delete(k Key) ([]*removal[Key, Value], error) {
+	t.Lock()
+	defer t.Unlock()
+
+	if exp, err := t.expireIfDue(k, time.Now()); exp != nil {
+		return exp, err
+	}
+	row, ok, err := t.peek(k)
+	if err != nil || !ok {
+		return nil, err
+	}
+	if t.isWatched() {
+		if err := t.record(&table.Change[Key, Value]{
+			Operation: table.Deleted,
+			Key:       k,
+			Old:       row,
+		}, nil); err != nil {
+			return nil, err
+		}
+	}
+	if err := t.removeRow(k, row); err != nil {
+		return nil, err
+	}
+	t.markDirty()
+	return nil, nil
+}
+
+// storeRow puts a row into the Table's Store, replacing the old one if it
+// exists, and brings the Table's indexes, versions, deadlines, and size up to
+// date. The entries must come from indexEntries, and oldSize from sizeOf, as
+// the old row may have been modified in place. The caller must hold the write
+// lock
+func (t *Table[Key, Value]) storeRow(
+	k Key, old []Value, exists bool, oldSize int64, row []Value,
+	entries []any, now time.Time,
+) error {
+	if err := t.rows.Put(k, row); err != nil {
+		return err
+	}
+	if exists {
+		t.unindex(k, old)
+	}
+	t.resize(t.sizeOf(k, row), oldSize)
+	t.track(k, entries, now)
+	return nil
+}
+
+// track adds a row that's in the Table's Store to its indexes, versions, and
+// deadlines. The caller must hold the write lock
+func (t *Table[Key, Value]) track(k Key, entries []any, now time.Time) {
+	for i, ix := range t.secondary {
+		ix.add(entries[i], k)
+	}
+	t.version++
+	t.versions[k] = t.version
+	t.touch(k, now)
+}
+
+// removeRow removes a row from the Table's Store, and from its indexes,
+// versions, and deadlines. The caller must hold the write lock
+func (t *Table[Key, Value]) removeRow(k Key, row []Value) error {
+	if err := t.rows.Remove(k); err != nil {
+		return err
+	}
+	t.untrack(k, row)
+	return nil
+}
+
+// untrack removes a row that's no longer in the Table's Store from its
+// indexes, versions, and deadlines. The caller must hold the write lock
+func (t *Table[Key, Value]) untrack(k Key, row []Value) {
+	t.resize(0, t.sizeOf(k, row))
+	t.unindex(k, row)
+	delete(t.versions, k)
+	delete(t.unset, k)
+	t.forget(k)
 }
 
 func (t *Table[_, _]) columnIndexes(c []table.ColumnName) ([]int, error) {
@@ -124,3 +124,100 @@ func TestBadSetter(t *testing.T) {
 	err = s("some-key", "one", "too", "many")
 	as.Errorf(err, fmt.Sprintf(table.ErrValueCountRequired, 2, 3))
 }
+
+func TestDeleter(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.Make[string, any]("name", "age")
+	setter, _ := tbl.Setter("name", "age")
+	getter, _ := tbl.Getter("name", "age")
+	deleter := tbl.Deleter()
+	as.NotNil(deleter)
+
+	as.Nil(setter("1", "bob", 42))
+	as.Nil(setter("2", "june", 36))
+
+	as.Nil(deleter("1"))
+	res, err := getter("1")
+	as.Nil(res)
+	as.EqualError(err, fmt.Sprintf(table.ErrKeyNotFound, "1"))
+
+	res, err = getter("2")
+	as.Nil(err)
+	as.Equal([]any{"june", 36}, res)
+
+	// Deleting a row that doesn't exist does nothing
+	as.Nil(deleter("1"))
+	as.Nil(deleter("3"))
+	as.Equal(1, tbl.Len())
+
+	as.Nil(setter("1", "robert", 43))
+	res, _ = getter("1")
+	as.Equal([]any{"robert", 43}, res)
+}
+
+func TestMultiGetter(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.Make[string, any]("name", "age")
+	setter, _ := tbl.Setter("name", "age")
+	as.Nil(setter("1", "bob", 42))
+	as.Nil(setter("2", "june", 36))
+
+	getter, err := tbl.MultiGetter("age")
+	as.Nil(err)
+	rows, missing, err := getter("1", "3", "2", "4")
+	as.Nil(err)
+	as.Equal(map[string][]any{"1": {42}, "2": {36}}, rows)
+	as.Equal([]string{"3", "4"}, missing)
+	as.Equal(table.Lookups{Hits: 2, Misses: 2}, stats(t, tbl).Lookups)
+
+	rows, missing, err = getter()
+	as.Nil(err)
+	as.Empty(rows)
+	as.Empty(missing)
+
+	_, err = tbl.MultiGetter("missing")
+	as.EqualError(err, fmt.Sprintf(table.ErrColumnNotFound, "missing"))
+
+	as.Nil(tbl.DropColumn("age"))
+	_, _, err = getter("1")
+	as.EqualError(err, fmt.Sprintf(table.ErrColumnDropped, "age"))
+}
+
+func BenchmarkGetter(b *testing.B) {
+	_, getter := makeBenchmarkTable(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for k := 0; k < 64; k++ {
+			_, _ = getter(k)
+		}
+	}
+}
+
+func BenchmarkMultiGetter(b *testing.B) {
+	tbl, _ := makeBenchmarkTable(b)
+	getter, _ := tbl.MultiGetter("age")
+	keys := make([]int, 64)
+	for i := range keys {
+		keys[i] = i
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _, _ = getter(keys...)
+	}
+}
+
+func makeBenchmarkTable(
+	b *testing.B,
+) (table.Table[int, any], table.Getter[int, any]) {
+	tbl, _ := internal.Make[int, any]("name", "age")
+	setter, _ := tbl.Setter("name", "age")
+	for i := 0; i < 1024; i++ {
+		if err := setter(i, "name", i); err != nil {
+			b.Fatal(err)
+		}
+	}
+	getter, _ := tbl.Getter("age")
+	return tbl, getter
+}
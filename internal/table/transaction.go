@@ -0,0 +1,336 @@
+package table
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/caravan/streaming/table"
+)
+
+type (
+	// transaction holds the write lock of every Table that has joined it
+	// until it's committed or rolled back
+	transaction struct {
+		members  []member
+		finished bool
+	}
+
+	// member is a Table's part in a transaction. Committing happens in two
+	// phases: every member first stages its Changes in its changelog, and
+	// only if all of them succeed are the Changes committed and applied
+	member interface {
+		prepare(now time.Time) error
+		apply(now time.Time) error
+		release()
+		notify()
+	}
+
+	// transactional is implemented by anything that belongs to a transaction
+	transactional interface {
+		joined() *transaction
+	}
+
+	// txn is the internal implementation of a table.Txn
+	txn[Key comparable, Value any] struct {
+		*transaction
+		tbl     *Table[Key, Value]
+		pending map[Key]*pending[Value]
+		order   []Key
+		changes []*table.Change[Key, Value]
+		staged  uint64
+		removed []*removal[Key, Value]
+		writes  []uint64
+	}
+
+	// pending is a row written by a txn. A nil row has been deleted
+	pending[Value any] struct {
+		row     []Value
+		entries []any
+		unset   unset
+	}
+)
+
+func (t *Table[Key, Value]) Begin() table.Txn[Key, Value] {
+	return t.join(&transaction{})
+}
+
+func (t *Table[Key, Value]) Join(
+	tx table.Transaction,
+) (table.Txn[Key, Value], error) {
+	m, ok := tx.(transactional)
+	if !ok {
+		return nil, fmt.Errorf(table.ErrTransactionType, tx)
+	}
+	tr := m.joined()
+	if tr.finished {
+		return nil, errors.New(table.ErrTransactionFinished)
+	}
+	for _, m := range tr.members {
+		if x, ok := m.(*txn[Key, Value]); ok && x.tbl == t {
+			return x, nil
+		}
+	}
+	return t.join(tr), nil
+}
+
+func (t *Table[Key, Value]) join(tr *transaction) *txn[Key, Value] {
+	t.Lock()
+	x := &txn[Key, Value]{
+		transaction: tr,
+		tbl:         t,
+		pending:     map[Key]*pending[Value]{},
+		writes:      make([]uint64, len(t.names)),
+	}
+	tr.members = append(tr.members, x)
+	return x
+}
+
+func (tr *transaction) joined() *transaction {
+	return tr
+}
+
+func (tr *transaction) Commit() error {
+	if tr.finished {
+		return errors.New(table.ErrTransactionFinished)
+	}
+	tr.finished = true
+	defer tr.release()
+
+	now := time.Now()
+	for _, m := range tr.members {
+		// If a later member fails, the Changes that earlier members have
+		// staged are never committed, so replaying ignores them
+		if err := m.prepare(now); err != nil {
+			return err
+		}
+	}
+	// A changelog or Store can only fail at this point if it's failing
+	// altogether, such as one on a disk that's gone away. The remaining
+	// members are still applied, and the first failure is reported
+	var res error
+	for _, m := range tr.members {
+		if err := m.apply(now); err != nil && res == nil {
+			res = err
+		}
+	}
+	return res
+}
+
+func (tr *transaction) Rollback() {
+	if tr.finished {
+		return
+	}
+	tr.finished = true
+	tr.release()
+}
+
+func (tr *transaction) release() {
+	for _, m := range tr.members {
+		m.release()
+	}
+	for _, m := range tr.members {
+		m.notify()
+	}
+}
+
+func (x *txn[Key, Value]) Getter(
+	c ...table.ColumnName,
+) (table.Getter[Key, Value], error) {
+	indexes, err := x.tbl.columnIndexes(c)
+	if err != nil {
+		return nil, err
+	}
+	return func(k Key) ([]Value, error) {
+		if x.finished {
+			return nil, errors.New(table.ErrTransactionFinished)
+		}
+		row, ok, err := x.row(k, x.tbl.rows.Get)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			x.tbl.countReads(indexes, 1)
+			return selectRow(k, row, indexes).values, nil
+		}
+		return nil, fmt.Errorf(table.ErrKeyNotFound, k)
+	}, nil
+}
+
+func (x *txn[Key, Value]) Setter(
+	c ...table.ColumnName,
+) (table.Setter[Key, Value], error) {
+	indexes, err := x.tbl.columnIndexes(c)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkColumnDuplicates(c); err != nil {
+		return nil, err
+	}
+	return func(k Key, v ...Value) error {
+		if x.finished {
+			return errors.New(table.ErrTransactionFinished)
+		}
+		if len(v) != len(indexes) {
+			return fmt.Errorf(
+				table.ErrValueCountRequired, len(indexes), len(v),
+			)
+		}
+		old, ok, err := x.row(k, x.tbl.peek)
+		if err != nil {
+			return err
+		}
+		row := make([]Value, len(x.tbl.names))
+		copy(row, old)
+		if !ok {
+			copy(row, x.tbl.defaults)
+		}
+		if err := x.tbl.assign(row, ok, x.unset(k), indexes, v); err != nil {
+			return err
+		}
+		entries, err := x.tbl.indexEntries(row)
+		if err != nil {
+			return err
+		}
+		x.write(k, &pending[Value]{
+			row:     row,
+			entries: entries,
+			unset:   x.tbl.nextUnset(x.unset(k), ok, indexes),
+		})
+		for _, i := range indexes {
+			x.writes[i]++
+		}
+		return nil
+	}, nil
+}
+
+func (x *txn[Key, Value]) Deleter() table.Deleter[Key] {
+	return func(k Key) error {
+		if x.finished {
+			return errors.New(table.ErrTransactionFinished)
+		}
+		if _, ok, err := x.row(k, x.tbl.peek); err != nil || !ok {
+			return err
+		}
+		x.write(k, &pending[Value]{})
+		return nil
+	}
+}
+
+// row returns the row of the Key as the txn sees it, reading it from the
+// Store with the provided function if the txn hasn't written it
+func (x *txn[Key, Value]) row(
+	k Key, read func(Key) ([]Value, bool, error),
+) ([]Value, bool, error) {
+	if p, ok := x.pending[k]; ok {
+		return p.row, p.row != nil, nil
+	}
+	if x.tbl.isExpired(k, time.Now()) {
+		return nil, false, nil
+	}
+	return read(k)
+}
+
+// unset returns the unset columns of the row of the Key as the txn sees it
+func (x *txn[Key, _]) unset(k Key) unset {
+	if p, ok := x.pending[k]; ok {
+		return p.unset
+	}
+	return x.tbl.unset[k]
+}
+
+func (x *txn[Key, Value]) write(k Key, p *pending[Value]) {
+	if _, ok := x.pending[k]; !ok {
+		x.order = append(x.order, k)
+	}
+	x.pending[k] = p
+}
+
+func (x *txn[Key, Value]) prepare(now time.Time) error {
+	t := x.tbl
+	for _, k := range x.order {
+		exp, err := t.expireIfDue(k, now)
+		x.removed = append(x.removed, exp...)
+		if err != nil {
+			return err
+		}
+		p := x.pending[k]
+		old, ok, err := t.peek(k)
+		if err != nil {
+			return err
+		}
+		c := &table.Change[Key, Value]{Key: k}
+		switch {
+		case p.row != nil && ok:
+			c.Operation = table.Updated
+			c.Old = old
+			c.New = p.row
+		case p.row != nil:
+			c.Operation = table.Inserted
+			c.New = p.row
+		case ok:
+			c.Operation = table.Deleted
+			c.Old = old
+		default:
+			continue // inserted and deleted within the transaction
+		}
+		x.changes = append(x.changes, c)
+	}
+	if t.changelog == nil || len(x.changes) == 0 {
+		return nil
+	}
+	var err error
+	x.staged, err = t.changelog.stage(x.changes, x.unset)
+	return err
+}
+
+func (x *txn[Key, Value]) apply(now time.Time) error {
+	t := x.tbl
+	if t.changelog != nil && len(x.changes) != 0 {
+		// Nothing is applied unless it would also be replayed
+		if err := t.changelog.commit(x.staged, x.changes); err != nil {
+			x.changes = nil
+			return err
+		}
+	}
+	var res error
+	for _, c := range x.changes {
+		var err error
+		if c.Operation == table.Deleted {
+			err = t.removeRow(c.Key, c.Old)
+		} else {
+			p := x.pending[c.Key]
+			exists := c.Operation == table.Updated
+			oldSize := t.sizeOf(c.Key, c.Old)
+			err = t.storeRow(
+				c.Key, c.Old, exists, oldSize, c.New, p.entries, now,
+			)
+			if err == nil {
+				t.storeUnset(c.Key, p.unset)
+			}
+		}
+		if err != nil {
+			if res == nil {
+				res = err
+			}
+			continue
+		}
+		t.publish(c)
+	}
+	if len(x.changes) != 0 {
+		t.markDirty()
+	}
+	for i, n := range x.writes {
+		t.usage[i].writes.Add(n)
+	}
+	x.removed = append(x.removed, t.takeEvictions()...)
+	return res
+}
+
+func (x *txn[_, _]) release() {
+	x.tbl.Unlock()
+}
+
+func (x *txn[_, _]) notify() {
+	x.tbl.notifyRemoved(x.removed)
+}
@@ -0,0 +1,269 @@
+package table_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/caravan/essentials/message"
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/config"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/streaming/internal/table"
+)
+
+type fakeTransaction struct{}
+
+func (fakeTransaction) Commit() error { return nil }
+func (fakeTransaction) Rollback()     {}
+
+func makeAccounts(t *testing.T, balances ...int) table.Table[int, int] {
+	tbl, err := internal.Make[int, int]("balance")
+	assert.Nil(t, err)
+	setter, _ := tbl.Setter("balance")
+	for k, b := range balances {
+		assert.Nil(t, setter(k, b))
+	}
+	return tbl
+}
+
+func transfer(
+	tx table.Transaction, from, to table.Txn[int, int], fk, tk, amount int,
+) error {
+	getter, _ := from.Getter("balance")
+	setter, _ := from.Setter("balance")
+	balance, err := getter(fk)
+	if err != nil {
+		return err
+	}
+	if err := setter(fk, balance[0]-amount); err != nil {
+		return err
+	}
+	getter, _ = to.Getter("balance")
+	setter, _ = to.Setter("balance")
+	balance, err = getter(tk)
+	if err != nil {
+		return err
+	}
+	if err := setter(tk, balance[0]+amount); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func TestTransaction(t *testing.T) {
+	as := assert.New(t)
+
+	tbl := makeAccounts(t, 100, 50)
+	getter, _ := tbl.Getter("balance")
+
+	tx := tbl.Begin()
+	txGetter, err := tx.Getter("balance")
+	as.Nil(err)
+	txSetter, err := tx.Setter("balance")
+	as.Nil(err)
+	as.Nil(txSetter(0, 70))
+	as.Nil(txSetter(2, 10))
+	as.Nil(tx.Deleter()(1))
+
+	// Read your own writes
+	res, err := txGetter(0)
+	as.Nil(err)
+	as.Equal([]int{70}, res)
+	res, _ = txGetter(2)
+	as.Equal([]int{10}, res)
+	_, err = txGetter(1)
+	as.EqualError(err, fmt.Sprintf(table.ErrKeyNotFound, 1))
+	as.Nil(tx.Deleter()(1))
+
+	as.Nil(tx.Commit())
+	res, _ = getter(0)
+	as.Equal([]int{70}, res)
+	res, _ = getter(2)
+	as.Equal([]int{10}, res)
+	_, err = getter(1)
+	as.EqualError(err, fmt.Sprintf(table.ErrKeyNotFound, 1))
+
+	// A finished transaction can no longer be used
+	as.EqualError(tx.Commit(), table.ErrTransactionFinished)
+	_, err = txGetter(0)
+	as.EqualError(err, table.ErrTransactionFinished)
+	as.EqualError(txSetter(0, 1), table.ErrTransactionFinished)
+	as.EqualError(tx.Deleter()(0), table.ErrTransactionFinished)
+	tx.Rollback()
+}
+
+func TestTransactionRollback(t *testing.T) {
+	as := assert.New(t)
+
+	tbl := makeAccounts(t, 100)
+	c := tbl.Changes()
+	defer c.Close()
+
+	tx := tbl.Begin()
+	txSetter, _ := tx.Setter("balance")
+	as.Nil(txSetter(0, 0))
+	as.Nil(txSetter(1, 0))
+	tx.Rollback()
+
+	getter, _ := tbl.Getter("balance")
+	res, _ := getter(0)
+	as.Equal([]int{100}, res)
+	_, err := getter(1)
+	as.EqualError(err, fmt.Sprintf(table.ErrKeyNotFound, 1))
+	as.Equal(1, tbl.Len())
+	_, ok := message.Poll[*table.Change[int, int]](c, 10*time.Millisecond)
+	as.False(ok)
+}
+
+func TestTransactionIsolation(t *testing.T) {
+	as := assert.New(t)
+
+	tbl := makeAccounts(t, 100)
+	getter, _ := tbl.Getter("balance")
+
+	tx := tbl.Begin()
+	txSetter, _ := tx.Setter("balance")
+	as.Nil(txSetter(0, 1))
+
+	read := make(chan []int)
+	go func() {
+		res, _ := getter(0)
+		read <- res
+	}()
+	select {
+	case <-read:
+		as.Fail("getter should wait for the transaction")
+	case <-time.After(20 * time.Millisecond):
+	}
+	as.Nil(txSetter(0, 2))
+	as.Nil(tx.Commit())
+	as.Equal([]int{2}, <-read)
+}
+
+func TestBalanceTransfer(t *testing.T) {
+	as := assert.New(t)
+
+	tbl := makeAccounts(t, 1000, 1000, 1000, 1000)
+	scan, _ := tbl.Scanner("balance")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				from, to := (i+j)%4, (i+j+1)%4
+				tx := tbl.Begin()
+				as.Nil(transfer(tx, tx, tx, from, to, j))
+			}
+		}(i)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			total := 0
+			as.Nil(scan(func(_ int, row []int) bool {
+				total += row[0]
+				return true
+			}))
+			as.Equal(4000, total)
+		}
+	}()
+	wg.Wait()
+	<-done
+}
+
+func TestCrossTableTransaction(t *testing.T) {
+	as := assert.New(t)
+
+	checking := makeAccounts(t, 100)
+	savings := makeAccounts(t, 20)
+	c := savings.Changes()
+	defer c.Close()
+
+	tx := checking.Begin()
+	txSavings, err := savings.Join(tx)
+	as.Nil(err)
+	again, err := savings.Join(tx)
+	as.Nil(err)
+	as.Equal(txSavings, again)
+	as.Nil(transfer(tx, tx, txSavings, 0, 0, 30))
+
+	getter, _ := checking.Getter("balance")
+	res, _ := getter(0)
+	as.Equal([]int{70}, res)
+	getter, _ = savings.Getter("balance")
+	res, _ = getter(0)
+	as.Equal([]int{50}, res)
+	as.Equal(&table.Change[int, int]{
+		Operation: table.Updated,
+		Key:       0,
+		Old:       []int{20},
+		New:       []int{50},
+	}, <-c.Receive())
+
+	// A failed transfer leaves both tables untouched
+	tx = checking.Begin()
+	txSavings, _ = savings.Join(tx)
+	err = transfer(tx, tx, txSavings, 0, 1, 30)
+	as.EqualError(err, fmt.Sprintf(table.ErrKeyNotFound, 1))
+	tx.Rollback()
+	res, _ = getter(0)
+	as.Equal([]int{50}, res)
+
+	_, err = savings.Join(tx)
+	as.EqualError(err, table.ErrTransactionFinished)
+	_, err = savings.Join(fakeTransaction{})
+	as.EqualError(err,
+		fmt.Sprintf(table.ErrTransactionType, fakeTransaction{}),
+	)
+}
+
+func TestTransactionChangelog(t *testing.T) {
+	as := assert.New(t)
+
+	path := t.TempDir() + "/changelog"
+	tbl, _ := internal.MakeWith[int, int](
+		[]table.ColumnName{"balance"}, config.ChangelogFile(path),
+	)
+	tx := tbl.Begin()
+	setter, _ := tx.Setter("balance")
+	as.Nil(setter(0, 10))
+	as.Nil(setter(1, 20))
+	as.Nil(setter(2, 30))
+	as.Nil(tx.Deleter()(2)) // never recorded
+	as.Nil(tx.Commit())
+
+	tbl, _ = internal.MakeWith[int, int](
+		[]table.ColumnName{"balance"}, config.ChangelogFile(path),
+	)
+	getter, _ := tbl.Getter("balance")
+	res, _ := getter(0)
+	as.Equal([]int{10}, res)
+	res, _ = getter(1)
+	as.Equal([]int{20}, res)
+	as.Equal(2, tbl.Len())
+}
+
+func TestTransactionIndex(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.MakeWith[int, any](
+		[]table.ColumnName{"owner"}, config.Index("owner"),
+	)
+	tx := tbl.Begin()
+	setter, _ := tx.Setter("owner")
+	as.Nil(setter(0, "bob"))
+	err := setter(1, []string{"bob"})
+	as.EqualError(err, fmt.Sprintf(table.ErrIndexValue, []string{"bob"}))
+	as.Nil(tx.Commit())
+
+	f, _ := tbl.Finder([]table.ColumnName{"owner"})
+	keys, _, _ := f("bob")
+	as.Equal([]int{0}, keys)
+}
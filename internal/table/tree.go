@@ -0,0 +1,240 @@
+package table
+
+import "github.com/caravan/streaming/table"
+
+type (
+	// treeStore is a Store that keeps rows in Key order using an AVL tree
+	treeStore[Key table.Ordered, Value any] struct {
+		root  *treeNode[Key, Value]
+		count int
+	}
+
+	treeNode[Key table.Ordered, Value any] struct {
+		key    Key
+		row    []Value
+		left   *treeNode[Key, Value]
+		right  *treeNode[Key, Value]
+		height int
+	}
+
+	// bound is one end of a range of Keys. An unset bound is unbounded
+	bound[Key table.Ordered] struct {
+		key Key
+		set bool
+	}
+)
+
+func boundAt[Key table.Ordered](k Key) bound[Key] {
+	return bound[Key]{key: k, set: true}
+}
+
+func (s *treeStore[Key, Value]) Get(k Key) ([]Value, bool, error) {
+	n := s.root
+	for n != nil {
+		switch {
+		case k < n.key:
+			n = n.left
+		case k > n.key:
+			n = n.right
+		default:
+			return n.row, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func (s *treeStore[Key, Value]) Put(k Key, row []Value) error {
+	s.root = s.insert(s.root, k, row)
+	return nil
+}
+
+func (s *treeStore[Key, _]) Remove(k Key) error {
+	s.root = s.delete(s.root, k)
+	return nil
+}
+
+func (s *treeStore[_, _]) Len() int {
+	return s.count
+}
+
+func (s *treeStore[Key, Value]) Each(fn func(Key, []Value) bool) error {
+	s.ascend(bound[Key]{}, bound[Key]{}, fn)
+	return nil
+}
+
+func (s *treeStore[_, _]) Close() error {
+	return nil
+}
+
+// ascend visits, in ascending Key order, the rows whose Keys are greater than
+// or equal to from and less than to
+func (s *treeStore[Key, Value]) ascend(
+	from, to bound[Key], fn func(Key, []Value) bool,
+) {
+	s.root.ascend(from, to, fn)
+}
+
+// descend visits, in descending Key order, the rows whose Keys are less than
+// or equal to from
+func (s *treeStore[Key, Value]) descend(
+	from bound[Key], fn func(Key, []Value) bool,
+) {
+	s.root.descend(from, fn)
+}
+
+func (s *treeStore[Key, Value]) insert(
+	n *treeNode[Key, Value], k Key, row []Value,
+) *treeNode[Key, Value] {
+	if n == nil {
+		s.count++
+		return &treeNode[Key, Value]{
+			key:    k,
+			row:    row,
+			height: 1,
+		}
+	}
+	switch {
+	case k < n.key:
+		n.left = s.insert(n.left, k, row)
+	case k > n.key:
+		n.right = s.insert(n.right, k, row)
+	default:
+		n.row = row
+		return n
+	}
+	return n.rebalance()
+}
+
+func (s *treeStore[Key, Value]) delete(
+	n *treeNode[Key, Value], k Key,
+) *treeNode[Key, Value] {
+	if n == nil {
+		return nil
+	}
+	switch {
+	case k < n.key:
+		n.left = s.delete(n.left, k)
+	case k > n.key:
+		n.right = s.delete(n.right, k)
+	default:
+		s.count--
+		if n.left == nil {
+			return n.right
+		}
+		if n.right == nil {
+			return n.left
+		}
+		m := n.right.min()
+		n.key, n.row = m.key, m.row
+		n.right = n.right.deleteMin()
+	}
+	return n.rebalance()
+}
+
+func (n *treeNode[Key, Value]) ascend(
+	from, to bound[Key], fn func(Key, []Value) bool,
+) bool {
+	if n == nil {
+		return true
+	}
+	if !from.set || from.key < n.key {
+		if !n.left.ascend(from, to, fn) {
+			return false
+		}
+	}
+	if to.set && n.key >= to.key {
+		return false
+	}
+	if !from.set || n.key >= from.key {
+		if !fn(n.key, n.row) {
+			return false
+		}
+	}
+	return n.right.ascend(from, to, fn)
+}
+
+func (n *treeNode[Key, Value]) descend(
+	from bound[Key], fn func(Key, []Value) bool,
+) bool {
+	if n == nil {
+		return true
+	}
+	if !from.set || n.key < from.key {
+		if !n.right.descend(from, fn) {
+			return false
+		}
+	}
+	if !from.set || n.key <= from.key {
+		if !fn(n.key, n.row) {
+			return false
+		}
+	}
+	return n.left.descend(from, fn)
+}
+
+func (n *treeNode[Key, Value]) min() *treeNode[Key, Value] {
+	for n.left != nil {
+		n = n.left
+	}
+	return n
+}
+
+func (n *treeNode[Key, Value]) deleteMin() *treeNode[Key, Value] {
+	if n.left == nil {
+		return n.right
+	}
+	n.left = n.left.deleteMin()
+	return n.rebalance()
+}
+
+func (n *treeNode[Key, Value]) rebalance() *treeNode[Key, Value] {
+	n.updateHeight()
+	switch balance := n.left.getHeight() - n.right.getHeight(); {
+	case balance > 1:
+		if n.left.left.getHeight() < n.left.right.getHeight() {
+			n.left = n.left.rotateLeft()
+		}
+		return n.rotateRight()
+	case balance < -1:
+		if n.right.right.getHeight() < n.right.left.getHeight() {
+			n.right = n.right.rotateRight()
+		}
+		return n.rotateLeft()
+	default:
+		return n
+	}
+}
+
+func (n *treeNode[Key, Value]) rotateLeft() *treeNode[Key, Value] {
+	r := n.right
+	n.right = r.left
+	r.left = n
+	n.updateHeight()
+	r.updateHeight()
+	return r
+}
+
+func (n *treeNode[Key, Value]) rotateRight() *treeNode[Key, Value] {
+	l := n.left
+	n.left = l.right
+	l.right = n
+	n.updateHeight()
+	l.updateHeight()
+	return l
+}
+
+func (n *treeNode[_, _]) getHeight() int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+func (n *treeNode[_, _]) updateHeight() {
+	l, r := n.left.getHeight(), n.right.getHeight()
+	if l > r {
+		n.height = l + 1
+	} else {
+		n.height = r + 1
+	}
+}
@@ -4,10 +4,12 @@ import "github.com/caravan/streaming/table"
 
 // Updater is the internal implementation of a table.Updater
 type Updater[Msg any, Key comparable, Value any] struct {
-	key     table.KeySelector[Msg, Key]
-	columns []table.Column[Msg, Value]
-	table   table.Table[Key, Value]
-	setter  table.Setter[Key, Value]
+	key       table.KeySelector[Msg, Key]
+	columns   []table.Column[Msg, Value]
+	tombstone table.Tombstone[Msg]
+	table     table.Table[Key, Value]
+	setter    table.Setter[Key, Value]
+	deleter   table.Deleter[Key]
 }
 
 // MakeUpdater instantiates a new internal Updater instance
@@ -15,6 +17,17 @@ func MakeUpdater[Msg any, Key comparable, Value any](
 	tbl table.Table[Key, Value],
 	key table.KeySelector[Msg, Key],
 	cols ...table.Column[Msg, Value],
+) (table.Updater[Msg, Key, Value], error) {
+	return MakeTombstoneUpdater(tbl, key, nil, cols...)
+}
+
+// MakeTombstoneUpdater instantiates a new internal Updater instance that
+// deletes the rows of any messages identified by the provided Tombstone
+func MakeTombstoneUpdater[Msg any, Key comparable, Value any](
+	tbl table.Table[Key, Value],
+	key table.KeySelector[Msg, Key],
+	tombstone table.Tombstone[Msg],
+	cols ...table.Column[Msg, Value],
 ) (table.Updater[Msg, Key, Value], error) {
 	names := make([]table.ColumnName, len(cols))
 	for i, c := range cols {
@@ -25,10 +38,12 @@ func MakeUpdater[Msg any, Key comparable, Value any](
 		return nil, err
 	}
 	return &Updater[Msg, Key, Value]{
-		key:     key,
-		columns: cols,
-		table:   tbl,
-		setter:  setter,
+		key:       key,
+		columns:   cols,
+		tombstone: tombstone,
+		table:     tbl,
+		setter:    setter,
+		deleter:   tbl.Deleter(),
 	}, nil
 }
 
@@ -41,9 +56,14 @@ func (u *Updater[Msg, _, Value]) Columns() []table.Column[Msg, Value] {
 }
 
 // Update adds or overwrites a message in the Table. The message is associated
-// with a Key that is selected from the message using the Table's KeySelector
+// with a Key that is selected from the message using the Table's KeySelector.
+// If the message is identified by the Updater's Tombstone, the Key's row is
+// deleted instead
 func (u *Updater[Msg, _, Value]) Update(msg Msg) error {
 	k := u.key(msg)
+	if u.tombstone != nil && u.tombstone(msg) {
+		return u.deleter(k)
+	}
 	row := make([]Value, len(u.columns))
 	for i, s := range u.columns {
 		row[i] = s.Select(msg)
@@ -80,3 +80,54 @@ func TestUpdater(t *testing.T) {
 	as.Nil(res)
 	as.EqualError(err, fmt.Sprintf(table.ErrKeyNotFound, missing))
 }
+
+func TestTombstoneUpdater(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.Make[string, any]("name", "age")
+	getter, _ := tbl.Getter("name", "age")
+
+	updater, err := internal.MakeTombstoneUpdater(tbl,
+		func(e *tableRow) string {
+			return e.key
+		},
+		func(e *tableRow) bool {
+			return e.name == ""
+		},
+		column.Make("name", func(r *tableRow) any {
+			return r.name
+		}),
+		column.Make("age", func(r *tableRow) any {
+			return r.age
+		}),
+	)
+	as.NotNil(updater)
+	as.Nil(err)
+
+	as.Nil(updater.Update(&tableRow{key: "1", name: "bill", age: 42}))
+	res, _ := getter("1")
+	as.Equal([]any{"bill", 42}, res)
+
+	as.Nil(updater.Update(&tableRow{key: "1"}))
+	res, err = getter("1")
+	as.Nil(res)
+	as.EqualError(err, fmt.Sprintf(table.ErrKeyNotFound, "1"))
+
+	as.Nil(updater.Update(&tableRow{key: "1"}))
+}
+
+func TestBadUpdater(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.Make[string, any]("name")
+	updater, err := internal.MakeUpdater(tbl,
+		func(e *tableRow) string {
+			return e.key
+		},
+		column.Make("age", func(r *tableRow) any {
+			return r.age
+		}),
+	)
+	as.Nil(updater)
+	as.EqualError(err, fmt.Sprintf(table.ErrColumnNotFound, "age"))
+}
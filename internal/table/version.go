@@ -0,0 +1,60 @@
+package table
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/caravan/streaming/table"
+)
+
+func (t *Table[Key, Value]) VersionedGetter(
+	c ...table.ColumnName,
+) (table.VersionedGetter[Key, Value], error) {
+	sel, err := t.selectColumns(c)
+	if err != nil {
+		return nil, err
+	}
+	return func(k Key) ([]Value, table.Version, error) {
+		t.RLock()
+		defer t.RUnlock()
+
+		indexes, err := t.positions(sel)
+		if err != nil {
+			return nil, 0, err
+		}
+		e, ok, err := t.rows.Get(k)
+		if err != nil {
+			return nil, 0, err
+		}
+		if ok && !t.isExpired(k, time.Now()) {
+			t.hits.Add(1)
+			t.countReads(indexes, 1)
+			return selectRow(k, e, indexes).values, t.versions[k], nil
+		}
+		t.misses.Add(1)
+		return nil, 0, fmt.Errorf(table.ErrKeyNotFound, k)
+	}, nil
+}
+
+func (t *Table[Key, Value]) CompareAndSetter(
+	c ...table.ColumnName,
+) (table.CompareAndSetter[Key, Value], error) {
+	sel, err := t.selectColumns(c)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkColumnDuplicates(c); err != nil {
+		return nil, err
+	}
+
+	return func(k Key, expected table.Version, v ...Value) (
+		table.Version, error,
+	) {
+		if len(v) != len(c) {
+			return 0, fmt.Errorf(table.ErrValueCountRequired, len(c), len(v))
+		}
+		res, exp, err := t.set(k, sel, v, &expected)
+		t.notifyRemoved(exp)
+		return res, err
+	}, nil
+}
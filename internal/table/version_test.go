@@ -0,0 +1,136 @@
+package table_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/config"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/streaming/internal/table"
+)
+
+func TestVersions(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.Make[string, any]("name", "age")
+	getter, err := tbl.VersionedGetter("age")
+	as.Nil(err)
+	cas, err := tbl.CompareAndSetter("name", "age")
+	as.Nil(err)
+	setter, _ := tbl.Setter("age")
+
+	_, v, err := getter("1")
+	as.Zero(v)
+	as.EqualError(err, fmt.Sprintf(table.ErrKeyNotFound, "1"))
+
+	// The zero Version inserts
+	v1, err := cas("1", 0, "bob", 42)
+	as.Nil(err)
+	as.NotZero(v1)
+	_, err = cas("1", 0, "bob", 42)
+	as.EqualError(err, fmt.Sprintf(table.ErrVersionConflict, "1", 0, v1))
+
+	res, v, err := getter("1")
+	as.Nil(err)
+	as.Equal([]any{42}, res)
+	as.Equal(v1, v)
+
+	// Any write moves the Version along
+	as.Nil(setter("1", 43))
+	_, v2, _ := getter("1")
+	as.Greater(v2, v1)
+
+	_, err = cas("1", v1, "bob", 44)
+	var conflict *table.VersionConflict
+	as.True(errors.As(err, &conflict))
+	as.Equal(&table.VersionConflict{
+		Key:      "1",
+		Expected: v1,
+		Found:    v2,
+	}, conflict)
+
+	v3, err := cas("1", v2, "bob", 44)
+	as.Nil(err)
+	as.Greater(v3, v2)
+
+	// Versions aren't reused when a row is inserted again
+	as.Nil(tbl.Deleter()("1"))
+	_, err = cas("1", v3, "bob", 45)
+	as.EqualError(err, fmt.Sprintf(table.ErrVersionConflict, "1", v3, 0))
+	v4, err := cas("1", 0, "bob", 45)
+	as.Nil(err)
+	as.Greater(v4, v3)
+
+	_, err = cas("1", v4, "bob")
+	as.EqualError(err, fmt.Sprintf(table.ErrValueCountRequired, 2, 1))
+}
+
+func TestBadVersioned(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.Make[string, any]("name")
+	getter, err := tbl.VersionedGetter("missing")
+	as.Nil(getter)
+	as.EqualError(err, fmt.Sprintf(table.ErrColumnNotFound, "missing"))
+
+	cas, err := tbl.CompareAndSetter("missing")
+	as.Nil(cas)
+	as.EqualError(err, fmt.Sprintf(table.ErrColumnNotFound, "missing"))
+
+	cas, err = tbl.CompareAndSetter("name", "name")
+	as.Nil(cas)
+	as.EqualError(err, fmt.Sprintf(table.ErrDuplicateColumnName, "name"))
+}
+
+func TestCompetingCompareAndSetters(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.Make[string, int]("count")
+	getter, _ := tbl.VersionedGetter("count")
+	cas, _ := tbl.CompareAndSetter("count")
+	_, _ = cas("counter", 0, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; {
+				res, v, _ := getter("counter")
+				_, err := cas("counter", v, res[0]+1)
+				if err == nil {
+					j++
+					continue
+				}
+				var conflict *table.VersionConflict
+				as.True(errors.As(err, &conflict))
+			}
+		}()
+	}
+	wg.Wait()
+
+	res, _, _ := getter("counter")
+	as.Equal([]int{1000}, res)
+}
+
+func TestExpiredVersion(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := internal.MakeWith[string, any](
+		[]table.ColumnName{"name"}, config.TTL(20*time.Millisecond),
+	)
+	cas, _ := tbl.CompareAndSetter("name")
+	v, err := cas("1", 0, "bob")
+	as.Nil(err)
+
+	time.Sleep(30 * time.Millisecond)
+	_, err = cas("1", v, "june")
+	as.EqualError(err, fmt.Sprintf(table.ErrVersionConflict, "1", v, 0))
+	_, err = cas("1", 0, "june")
+	as.Nil(err)
+}
@@ -0,0 +1,331 @@
+package table
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/config"
+)
+
+type (
+	// joinView is a View whose rows join the rows of a left Table with those
+	// of a right Table. Each left row is matched with the right row whose Key
+	// is its foreign Key. Rows are rederived from the current state of both
+	// sources whenever either of them reports a Change, so the View converges
+	// on the sources regardless of how their Changes interleave
+	joinView[Key, Foreign comparable, Value any] struct {
+		table.Table[Key, Value]
+		kind     table.JoinType
+		foreign  foreignKey[Key, Foreign, Value]
+		left     table.MultiGetter[Key, Value]
+		right    table.MultiGetter[Foreign, Value]
+		lWidth   int
+		rWidth   int
+		setter   table.Setter[Key, Value]
+		deleter  table.Deleter[Key]
+		lChanges topic.Consumer[*table.Change[Key, Value]]
+		rChanges topic.Consumer[*table.Change[Foreign, Value]]
+
+		// The following are only accessed by the goroutine that follows
+		// the sources, once the View has been populated
+		refs    map[Foreign]map[Key]struct{}
+		targets map[Key]Foreign
+		rows    map[Key]struct{}
+
+		follower
+	}
+
+	// follower tracks whether a View is still following its sources in the
+	// background. It stops when the View is closed, or when a Change can't
+	// be applied, in which case the error is kept for Err
+	follower struct {
+		once   sync.Once
+		err    error
+		closed chan struct{}
+		done   chan struct{}
+	}
+
+	// foreignKey selects the Key of the right row that a left row joins. It
+	// returns false if the left row doesn't join any right row
+	foreignKey[Key, Foreign comparable, Value any] func(
+		Key, []Value,
+	) (Foreign, bool, error)
+)
+
+// MakeKeyJoin instantiates a View that joins the rows of two Tables that share
+// the same Key
+func MakeKeyJoin[Key comparable, Value any](
+	left, right table.Table[Key, Value], kind table.JoinType,
+	lc, rc []table.ColumnName, o ...config.Option,
+) (table.View[Key, Value], error) {
+	return makeJoin(left, right, kind, lc, rc, lc, o,
+		func(k Key, _ []Value) (Key, bool, error) {
+			return k, true, nil
+		},
+	)
+}
+
+// MakeForeignKeyJoin instantiates a View that joins each row of the left Table
+// with the row of the right Table whose Key is held in the left row's foreign
+// Key column. A left row whose foreign Key is nil joins no right row
+func MakeForeignKeyJoin[Key, Foreign comparable, Value any](
+	left table.Table[Key, Value], fk table.ColumnName,
+	right table.Table[Foreign, Value], kind table.JoinType,
+	lc, rc []table.ColumnName, o ...config.Option,
+) (table.View[Key, Value], error) {
+	read := make([]table.ColumnName, len(lc), len(lc)+1)
+	copy(read, lc)
+	read = append(read, fk)
+	return makeJoin(left, right, kind, lc, rc, read, o,
+		func(_ Key, row []Value) (Foreign, bool, error) {
+			var zero Foreign
+			v := any(row[len(lc)])
+			if v == nil {
+				return zero, false, nil
+			}
+			if res, ok := v.(Foreign); ok {
+				return res, true, nil
+			}
+			return zero, false, fmt.Errorf(table.ErrForeignKeyType, v)
+		},
+	)
+}
+
+func makeJoin[Key, Foreign comparable, Value any](
+	left table.Table[Key, Value], right table.Table[Foreign, Value],
+	kind table.JoinType, lc, rc, read []table.ColumnName, o []config.Option,
+	foreign foreignKey[Key, Foreign, Value],
+) (table.View[Key, Value], error) {
+	if kind != table.InnerJoin && kind != table.LeftJoin {
+		return nil, fmt.Errorf(table.ErrInvalidJoinType, kind)
+	}
+	lGet, err := left.MultiGetter(read...)
+	if err != nil {
+		return nil, err
+	}
+	rGet, err := right.MultiGetter(rc...)
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]table.ColumnName, 0, len(lc)+len(rc))
+	columns = append(columns, lc...)
+	columns = append(columns, rc...)
+	derived, err := MakeWith[Key, Value](columns, o...)
+	if err != nil {
+		return nil, err
+	}
+	setter, err := derived.Setter(columns...)
+	if err != nil {
+		_ = derived.Close()
+		return nil, err
+	}
+
+	v := &joinView[Key, Foreign, Value]{
+		Table:    derived,
+		kind:     kind,
+		foreign:  foreign,
+		left:     lGet,
+		right:    rGet,
+		lWidth:   len(lc),
+		rWidth:   len(rc),
+		setter:   setter,
+		deleter:  derived.Deleter(),
+		lChanges: left.Changes(),
+		rChanges: right.Changes(),
+		refs:     map[Foreign]map[Key]struct{}{},
+		targets:  map[Key]Foreign{},
+		rows:     map[Key]struct{}{},
+		follower: makeFollower(),
+	}
+
+	// The sources are watched before they're read, so that no Change made
+	// while the View is being populated is missed
+	keys, err := left.Keys()
+	if err != nil {
+		v.lChanges.Close()
+		v.rChanges.Close()
+		_ = derived.Close()
+		return nil, err
+	}
+	for _, k := range keys {
+		if err := v.refresh(k); err != nil {
+			v.lChanges.Close()
+			v.rChanges.Close()
+			_ = derived.Close()
+			return nil, err
+		}
+	}
+	go v.follow()
+	return v, nil
+}
+
+func makeFollower() follower {
+	return follower{
+		closed: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// halt stops the View from following its sources, and waits for the Change
+// that it may be applying
+func (f *follower) halt() {
+	f.stop(nil)
+	<-f.done
+}
+
+func (f *follower) IsClosed() <-chan struct{} {
+	return f.closed
+}
+
+func (f *follower) Err() error {
+	select {
+	case <-f.closed:
+		return f.err
+	default:
+		return nil
+	}
+}
+
+// stop closes the View, recording the error that made it stop, if any. Only
+// the first call has any effect
+func (f *follower) stop(err error) {
+	f.once.Do(func() {
+		f.err = err
+		close(f.closed)
+	})
+}
+
+// Close stops the View from following its sources, and then closes the Table
+// that holds its rows
+func (v *joinView[_, _, _]) Close() error {
+	v.halt()
+	return v.Table.Close()
+}
+
+// follow rederives the rows affected by each Change made to the sources,
+// until the View is closed or a row can't be rederived
+func (v *joinView[Key, _, _]) follow() {
+	defer func() {
+		v.lChanges.Close()
+		v.rChanges.Close()
+		close(v.done)
+	}()
+	for {
+		select {
+		case <-v.closed:
+			return
+		case c, ok := <-v.lChanges.Receive():
+			if !ok {
+				v.stop(errors.New(table.ErrSourceClosed))
+				return
+			}
+			if err := v.refresh(c.Key); err != nil {
+				v.stop(err)
+				return
+			}
+		case c, ok := <-v.rChanges.Receive():
+			if !ok {
+				v.stop(errors.New(table.ErrSourceClosed))
+				return
+			}
+			keys := make([]Key, 0, len(v.refs[c.Key]))
+			for k := range v.refs[c.Key] {
+				keys = append(keys, k)
+			}
+			for _, k := range keys {
+				if err := v.refresh(k); err != nil {
+					v.stop(err)
+					return
+				}
+			}
+		}
+	}
+}
+
+// refresh rederives the row of the View for a Key of the left source
+func (v *joinView[Key, Foreign, Value]) refresh(k Key) error {
+	found, _, err := v.left(k)
+	if err != nil {
+		return err
+	}
+	row, ok := found[k]
+	if !ok {
+		v.unreference(k)
+		return v.remove(k)
+	}
+	f, ok, err := v.foreign(k, row)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		v.unreference(k)
+	} else {
+		v.reference(k, f)
+	}
+
+	var right []Value
+	if ok {
+		found, _, err := v.right(f)
+		if err != nil {
+			return err
+		}
+		right, ok = found[f]
+	}
+	if !ok {
+		if v.kind == table.InnerJoin {
+			return v.remove(k)
+		}
+		right = make([]Value, v.rWidth)
+	}
+	res := make([]Value, 0, v.lWidth+len(right))
+	res = append(res, row[:v.lWidth]...)
+	res = append(res, right...)
+	if err := v.setter(k, res...); err != nil {
+		return err
+	}
+	v.rows[k] = struct{}{}
+	return nil
+}
+
+func (v *joinView[Key, _, _]) remove(k Key) error {
+	if _, ok := v.rows[k]; !ok {
+		return nil
+	}
+	delete(v.rows, k)
+	return v.deleter(k)
+}
+
+// reference records that the left row of a Key joins the right row of a
+// foreign Key, so that Changes to the right row rederive it
+func (v *joinView[Key, Foreign, _]) reference(k Key, f Foreign) {
+	if old, ok := v.targets[k]; ok {
+		if old == f {
+			return
+		}
+		v.unreference(k)
+	}
+	v.targets[k] = f
+	keys, ok := v.refs[f]
+	if !ok {
+		keys = map[Key]struct{}{}
+		v.refs[f] = keys
+	}
+	keys[k] = struct{}{}
+}
+
+func (v *joinView[Key, _, _]) unreference(k Key) {
+	f, ok := v.targets[k]
+	if !ok {
+		return
+	}
+	delete(v.targets, k)
+	if keys := v.refs[f]; keys != nil {
+		delete(keys, k)
+		if len(keys) == 0 {
+			delete(v.refs, f)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package table_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/caravan/streaming/table"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/streaming/internal/table"
+)
+
+// eventually waits for the View to hold the expected row for the Key, or to
+// hold no row for it if the expected row is nil
+func eventually[Key comparable](
+	t *testing.T, v table.View[Key, any], k Key, expected []any,
+) {
+	get, err := v.MultiGetter(v.Columns()...)
+	assert.Nil(t, err)
+	assert.Eventually(t, func() bool {
+		found, _, err := get(k)
+		if err != nil {
+			return false
+		}
+		row, ok := found[k]
+		if expected == nil {
+			return !ok
+		}
+		return ok && assert.ObjectsAreEqual(expected, row)
+	}, time.Second, time.Millisecond)
+}
+
+func TestKeyJoin(t *testing.T) {
+	as := assert.New(t)
+
+	customers, _ := internal.Make[string, any]("name", "tier")
+	accounts, _ := internal.Make[string, any]("balance")
+	setCustomer, _ := customers.Setter("name", "tier")
+	setAccount, _ := accounts.Setter("balance")
+	as.Nil(setCustomer("1", "bill", "gold"))
+	as.Nil(setAccount("1", 100))
+	as.Nil(setCustomer("2", "jane", "silver"))
+
+	inner, err := internal.MakeKeyJoin(customers, accounts, table.InnerJoin,
+		[]table.ColumnName{"name"}, []table.ColumnName{"balance"},
+	)
+	as.Nil(err)
+	defer inner.Close()
+	left, err := internal.MakeKeyJoin(customers, accounts, table.LeftJoin,
+		[]table.ColumnName{"name"}, []table.ColumnName{"balance"},
+	)
+	as.Nil(err)
+	defer left.Close()
+
+	as.Equal([]table.ColumnName{"name", "balance"}, inner.Columns())
+	as.Equal(1, inner.Len())
+	as.Equal(2, left.Len())
+	eventually[string](t, inner, "2", nil)
+	eventually(t, left, "2", []any{"jane", nil})
+
+	as.Nil(setAccount("2", 50))
+	eventually(t, inner, "2", []any{"jane", 50})
+	eventually(t, left, "2", []any{"jane", 50})
+
+	as.Nil(setCustomer("1", "william", "gold"))
+	eventually(t, inner, "1", []any{"william", 100})
+
+	as.Nil(accounts.Deleter()("1"))
+	eventually[string](t, inner, "1", nil)
+	eventually(t, left, "1", []any{"william", nil})
+
+	as.Nil(customers.Deleter()("2"))
+	eventually[string](t, inner, "2", nil)
+	eventually[string](t, left, "2", nil)
+
+	// Rows of the right source alone don't derive rows
+	as.Nil(setAccount("3", 10))
+	as.Nil(setCustomer("4", "june", "bronze"))
+	eventually(t, left, "4", []any{"june", nil})
+	as.Equal(0, inner.Len())
+	as.Equal(2, left.Len())
+}
+
+func TestForeignKeyJoin(t *testing.T) {
+	as := assert.New(t)
+
+	accounts, _ := internal.Make[string, any]("owner", "balance")
+	customers, _ := internal.Make[int, any]("name")
+	setAccount, _ := accounts.Setter("owner", "balance")
+	setCustomer, _ := customers.Setter("name")
+	as.Nil(setCustomer(1, "bill"))
+	as.Nil(setCustomer(2, "jane"))
+	as.Nil(setAccount("a", 1, 100))
+	as.Nil(setAccount("b", 1, 50))
+	as.Nil(setAccount("c", nil, 10))
+
+	v, err := internal.MakeForeignKeyJoin(
+		accounts, "owner", customers, table.LeftJoin,
+		[]table.ColumnName{"balance"}, []table.ColumnName{"name"},
+	)
+	as.Nil(err)
+	defer v.Close()
+
+	eventually(t, v, "a", []any{100, "bill"})
+	eventually(t, v, "b", []any{50, "bill"})
+	eventually(t, v, "c", []any{10, nil})
+
+	// Changing the right row rederives every left row that references it
+	as.Nil(setCustomer(1, "william"))
+	eventually(t, v, "a", []any{100, "william"})
+	eventually(t, v, "b", []any{50, "william"})
+
+	// Moving a left row to another right row stops it following the first
+	as.Nil(setAccount("b", 2, 50))
+	eventually(t, v, "b", []any{50, "jane"})
+	as.Nil(setCustomer(1, "bill"))
+	eventually(t, v, "a", []any{100, "bill"})
+	eventually(t, v, "b", []any{50, "jane"})
+
+	as.Nil(setAccount("c", 2, 10))
+	eventually(t, v, "c", []any{10, "jane"})
+}
+
+func TestJoinErrors(t *testing.T) {
+	as := assert.New(t)
+
+	accounts, _ := internal.Make[string, any]("owner", "balance")
+	customers, _ := internal.Make[int, any]("name")
+	setAccount, _ := accounts.Setter("owner", "balance")
+	cols := []table.ColumnName{"balance"}
+
+	_, err := internal.MakeForeignKeyJoin(accounts, "owner", customers, 9,
+		cols, []table.ColumnName{"name"},
+	)
+	as.EqualError(err, fmt.Sprintf(table.ErrInvalidJoinType, 9))
+
+	_, err = internal.MakeForeignKeyJoin(
+		accounts, "missing", customers, table.InnerJoin,
+		cols, []table.ColumnName{"name"},
+	)
+	as.EqualError(err, fmt.Sprintf(table.ErrColumnNotFound, "missing"))
+
+	_, err = internal.MakeForeignKeyJoin(
+		accounts, "owner", customers, table.InnerJoin,
+		cols, []table.ColumnName{"balance"},
+	)
+	as.EqualError(err, fmt.Sprintf(table.ErrColumnNotFound, "balance"))
+
+	_, err = internal.MakeKeyJoin(accounts, accounts, table.InnerJoin,
+		cols, cols,
+	)
+	as.EqualError(err, fmt.Sprintf(table.ErrDuplicateColumnName, "balance"))
+
+	as.Nil(setAccount("a", "not an int", 100))
+	_, err = internal.MakeForeignKeyJoin(
+		accounts, "owner", customers, table.InnerJoin,
+		cols, []table.ColumnName{"name"},
+	)
+	as.EqualError(err, fmt.Sprintf(table.ErrForeignKeyType, "not an int"))
+}
+
+func TestJoinClose(t *testing.T) {
+	as := assert.New(t)
+
+	left, _ := internal.Make[string, any]("l")
+	right, _ := internal.Make[string, any]("r")
+	setLeft, _ := left.Setter("l")
+	v, err := internal.MakeKeyJoin(left, right, table.LeftJoin,
+		[]table.ColumnName{"l"}, []table.ColumnName{"r"},
+	)
+	as.Nil(err)
+	as.Nil(setLeft("1", 1))
+	eventually(t, v, "1", []any{1, nil})
+
+	v.Close()
+	v.Close()
+	<-v.IsClosed()
+	as.Nil(v.Err())
+	as.Nil(setLeft("2", 2))
+	time.Sleep(10 * time.Millisecond)
+	as.Equal(1, v.Len())
+}
+
+func TestJoinFollowError(t *testing.T) {
+	as := assert.New(t)
+
+	accounts, _ := internal.Make[string, any]("owner", "balance")
+	customers, _ := internal.Make[int, any]("name")
+	setAccount, _ := accounts.Setter("owner", "balance")
+	v, err := internal.MakeForeignKeyJoin(
+		accounts, "owner", customers, table.LeftJoin,
+		[]table.ColumnName{"balance"}, []table.ColumnName{"name"},
+	)
+	as.Nil(err)
+	as.Nil(v.Err())
+
+	// A row that can't be rederived closes the View
+	as.Nil(setAccount("a", "not an int", 100))
+	select {
+	case <-v.IsClosed():
+	case <-time.After(time.Second):
+		as.Fail("view wasn't closed")
+	}
+	as.EqualError(v.Err(), fmt.Sprintf(table.ErrForeignKeyType, "not an int"))
+	v.Close()
+}
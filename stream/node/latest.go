@@ -0,0 +1,71 @@
+package node
+
+import (
+	"github.com/caravan/streaming/stream"
+	"github.com/caravan/streaming/stream/context"
+)
+
+// CombineLatest accepts two Processors for the sake of combining their most
+// recent results. Nothing is forwarded until both Processors have produced a
+// message. From then on, every message produced by either Processor is
+// combined with the latest message seen from the other using the provided
+// BinaryOperator, and the result is forwarded
+func CombineLatest[Left, Right, Out any](
+	left stream.Processor[stream.Source, Left],
+	right stream.Processor[stream.Source, Right],
+	combiner BinaryOperator[Left, Right, Out],
+) stream.Processor[stream.Source, Out] {
+	return combineLatest(left, right, combiner, true)
+}
+
+// WithLatestFrom accepts a primary and a secondary Processor. Every message
+// produced by the primary Processor is combined with the latest message seen
+// from the secondary Processor using the provided BinaryOperator, and the
+// result is forwarded. Messages from the secondary Processor are never
+// forwarded on their own, and primary messages that arrive before the
+// secondary Processor has produced anything are dropped
+func WithLatestFrom[Primary, Secondary, Out any](
+	primary stream.Processor[stream.Source, Primary],
+	secondary stream.Processor[stream.Source, Secondary],
+	combiner BinaryOperator[Primary, Secondary, Out],
+) stream.Processor[stream.Source, Out] {
+	return combineLatest(primary, secondary, combiner, false)
+}
+
+func combineLatest[Left, Right, Out any](
+	left stream.Processor[stream.Source, Left],
+	right stream.Processor[stream.Source, Right],
+	combiner BinaryOperator[Left, Right, Out],
+	forwardRight bool,
+) stream.Processor[stream.Source, Out] {
+	return func(c *context.Context[stream.Source, Out]) {
+		leftOut := make(chan Left)
+		rightOut := make(chan Right)
+		left.Start(context.WithOut(c, leftOut))
+		right.Start(context.WithOut(c, rightOut))
+
+		var latestLeft Left
+		var latestRight Right
+		var hasLeft, hasRight bool
+
+		for {
+			select {
+			case <-c.Done:
+				return
+			case msg := <-leftOut:
+				latestLeft, hasLeft = msg, true
+				if !hasRight {
+					continue
+				}
+			case msg := <-rightOut:
+				latestRight, hasRight = msg, true
+				if !hasLeft || !forwardRight {
+					continue
+				}
+			}
+			if !c.ForwardResult(combiner(latestLeft, latestRight)) {
+				return
+			}
+		}
+	}
+}
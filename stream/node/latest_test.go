@@ -0,0 +1,98 @@
+package node_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caravan/essentials"
+	"github.com/caravan/essentials/message"
+	"github.com/caravan/streaming/stream/node"
+	"github.com/stretchr/testify/assert"
+
+	internal "github.com/caravan/streaming/internal/stream"
+)
+
+func TestCombineLatest(t *testing.T) {
+	as := assert.New(t)
+
+	leftTopic := essentials.NewTopic[int]()
+	rightTopic := essentials.NewTopic[int]()
+	outTopic := essentials.NewTopic[int]()
+
+	s := internal.Make(
+		node.CombineLatest(
+			node.TopicConsumer(leftTopic),
+			node.TopicConsumer(rightTopic),
+			joinSum,
+		),
+		node.TopicProducer(outTopic),
+	).Start()
+
+	as.NotNil(s)
+	lp := leftTopic.NewProducer()
+	rp := rightTopic.NewProducer()
+	lp.Send() <- 1 // nothing from the right yet
+	time.Sleep(10 * time.Millisecond)
+	rp.Send() <- 10
+	time.Sleep(10 * time.Millisecond)
+	lp.Send() <- 2
+	time.Sleep(10 * time.Millisecond)
+	rp.Send() <- 20
+	time.Sleep(10 * time.Millisecond)
+	rp.Send() <- 30
+	rp.Close()
+	lp.Close()
+
+	c := outTopic.NewConsumer()
+	as.Equal(11, <-c.Receive())
+	as.Equal(12, <-c.Receive())
+	as.Equal(22, <-c.Receive())
+	as.Equal(32, <-c.Receive())
+	c.Close()
+
+	as.Nil(s.Stop())
+}
+
+func TestWithLatestFrom(t *testing.T) {
+	as := assert.New(t)
+
+	primaryTopic := essentials.NewTopic[int]()
+	secondaryTopic := essentials.NewTopic[int]()
+	outTopic := essentials.NewTopic[int]()
+
+	s := internal.Make(
+		node.WithLatestFrom(
+			node.TopicConsumer(primaryTopic),
+			node.TopicConsumer(secondaryTopic),
+			joinSum,
+		),
+		node.TopicProducer(outTopic),
+	).Start()
+
+	as.NotNil(s)
+	pp := primaryTopic.NewProducer()
+	sp := secondaryTopic.NewProducer()
+	pp.Send() <- 1 // dropped, nothing from the secondary yet
+	time.Sleep(10 * time.Millisecond)
+	sp.Send() <- 10 // never forwarded on its own
+	time.Sleep(10 * time.Millisecond)
+	pp.Send() <- 2
+	time.Sleep(10 * time.Millisecond)
+	sp.Send() <- 20
+	time.Sleep(10 * time.Millisecond)
+	sp.Send() <- 30
+	time.Sleep(10 * time.Millisecond)
+	pp.Send() <- 3
+	sp.Close()
+	pp.Close()
+
+	c := outTopic.NewConsumer()
+	as.Equal(12, <-c.Receive())
+	as.Equal(33, <-c.Receive())
+	e, ok := message.Poll[int](c, 50*time.Millisecond) // nothing else
+	as.Zero(e)
+	as.False(ok)
+	c.Close()
+
+	as.Nil(s.Stop())
+}
@@ -1,11 +1,18 @@
 package node
 
 import (
+	"fmt"
+
 	"github.com/caravan/streaming/stream"
 	"github.com/caravan/streaming/stream/context"
 	"github.com/caravan/streaming/table"
 )
 
+// Error messages
+const (
+	ErrInvalidBatchSize = "batch size must be greater than zero: %d"
+)
+
 // TableLookup performs a lookup on a table using the provided message. The Key
 // extracts a Key from this message and uses it to perform the lookup against
 // the Table. The Column returned by the lookup is forwarded to the next
@@ -34,6 +41,149 @@ func TableLookup[Msg any, Key comparable, Value any](
 	}, nil
 }
 
+// TableBatchLookup performs lookups on a table like TableLookup, but groups
+// the messages that are waiting to be processed, up to the specified size, so
+// that their Keys are looked up while only acquiring the Table's lock once.
+// It never waits for a batch to fill, so it adds no latency when messages are
+// sparse. Results are forwarded in the order the messages arrived, and a Key
+// that isn't found is reported as an error
+func TableBatchLookup[Msg any, Key comparable, Value any](
+	t table.Table[Key, Value],
+	c table.ColumnName,
+	k table.KeySelector[Msg, Key],
+	size int,
+) (stream.Processor[Msg, Value], error) {
+	if size < 1 {
+		return nil, fmt.Errorf(ErrInvalidBatchSize, size)
+	}
+	getColumns, err := t.MultiGetter(c)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *context.Context[Msg, Value]) {
+		keys := make([]Key, 0, size)
+		for {
+			batch, ok := fetchBatch(c, size)
+			if !ok {
+				return
+			}
+			keys = keys[:0]
+			for _, msg := range batch {
+				keys = append(keys, k(msg))
+			}
+			rows, _, e := getColumns(keys...)
+			if e != nil {
+				if !c.Error(e) {
+					return
+				}
+				continue
+			}
+			for _, key := range keys {
+				if row, ok := rows[key]; !ok {
+					if !c.Errorf(table.ErrKeyNotFound, key) {
+						return
+					}
+				} else if !c.ForwardResult(row[0]) {
+					return
+				}
+			}
+		}
+	}, nil
+}
+
+// fetchBatch waits for a message, and then takes any others that are already
+// waiting, up to the specified size
+func fetchBatch[Msg, Out any](
+	c *context.Context[Msg, Out], size int,
+) ([]Msg, bool) {
+	msg, ok := c.FetchMessage()
+	if !ok {
+		return nil, false
+	}
+	res := []Msg{msg}
+	for len(res) < size {
+		select {
+		case <-c.Done:
+			return nil, false
+		case msg := <-c.In:
+			res = append(res, msg)
+		default:
+			return res, true
+		}
+	}
+	return res, true
+}
+
+// TableFieldLookup performs a lookup on a StructTable using the provided
+// message. The Key extracts a Key from this message and uses it to perform the
+// lookup against the Table. The typed Value of the Field returned by the
+// lookup is forwarded to the next Processor
+func TableFieldLookup[Msg any, Key comparable, Row, Value any](
+	t table.StructTable[Key, Row],
+	f table.Field[Row, Value],
+	k table.KeySelector[Msg, Key],
+) (stream.Processor[Msg, Value], error) {
+	getField, err := table.GetField(t, f)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *context.Context[Msg, Value]) {
+		for {
+			if msg, ok := c.FetchMessage(); !ok {
+				return
+			} else if res, e := getField(k(msg)); e != nil {
+				if !c.Error(e) {
+					return
+				}
+			} else if !c.ForwardResult(res) {
+				return
+			}
+		}
+	}, nil
+}
+
+// TableLookupBy performs a lookup on a table's secondary index using the
+// provided message. The Value extracts a Value from this message and uses it
+// to find every row whose indexed column holds it. The Column of each row
+// found is forwarded to the next Processor. If no row is found, an error is
+// reported instead
+func TableLookupBy[Msg any, Key comparable, Value any](
+	t table.Table[Key, Value],
+	idx table.ColumnName,
+	c table.ColumnName,
+	v table.ValueSelector[Msg, Value],
+) (stream.Processor[Msg, Value], error) {
+	findRows, err := t.Finder([]table.ColumnName{idx}, c)
+	if err != nil {
+		return nil, err
+	}
+	find := func(msg Msg) ([][]Value, error) {
+		value := v(msg)
+		_, rows, err := findRows(value)
+		if err == nil && len(rows) == 0 {
+			err = fmt.Errorf(table.ErrIndexValueNotFound, value)
+		}
+		return rows, err
+	}
+	return func(c *context.Context[Msg, Value]) {
+		for {
+			if msg, ok := c.FetchMessage(); !ok {
+				return
+			} else if rows, e := find(msg); e != nil {
+				if !c.Error(e) {
+					return
+				}
+			} else {
+				for _, row := range rows {
+					if !c.ForwardResult(row[0]) {
+						return
+					}
+				}
+			}
+		}
+	}, nil
+}
+
 // TableUpdater constructs a processor that sends all messages it sees to the
 // provided table Updater
 func TableUpdater[Msg any, Key comparable, Value any](
@@ -53,3 +203,232 @@ func TableUpdater[Msg any, Key comparable, Value any](
 		}
 	}
 }
+
+// TableDeleter constructs a processor that deletes the row associated with the
+// Key selected from each message it sees, and then forwards the message
+func TableDeleter[Msg any, Key comparable, Value any](
+	t table.Table[Key, Value],
+	k table.KeySelector[Msg, Key],
+) stream.Processor[Msg, Msg] {
+	deleteRow := t.Deleter()
+	return func(c *context.Context[Msg, Msg]) {
+		for {
+			if msg, ok := c.FetchMessage(); !ok {
+				return
+			} else if e := deleteRow(k(msg)); e != nil {
+				if !c.Error(e) {
+					return
+				}
+			} else if !c.ForwardResult(msg) {
+				return
+			}
+		}
+	}
+}
+
+// TableChanges constructs a processor that forwards every Change made to the
+// provided Table while the processor is running. Each start of the processor
+// watches the Table anew, from the moment it starts. The processor stops once
+// the Table is closed
+func TableChanges[Key comparable, Value any](
+	t table.Table[Key, Value],
+) stream.Processor[stream.Source, *table.Change[Key, Value]] {
+	return func(c *context.Context[stream.Source, *table.Change[Key, Value]]) {
+		consumer := t.Changes()
+		defer consumer.Close()
+		changes := consumer.Receive()
+		for {
+			if _, ok := c.FetchMessage(); !ok {
+				return
+			}
+			select {
+			case <-c.Done:
+				return
+			case change, ok := <-changes:
+				if !ok || !c.ForwardResult(change) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// TableReplicator constructs a processor that applies every Change it sees to
+// the provided Table, and then forwards the Change. Paired with a changelog
+// Topic or node.TableChanges, it maintains a replica of another Table that
+// has the same columns
+func TableReplicator[Key comparable, Value any](
+	t table.Table[Key, Value],
+) (
+	stream.Processor[*table.Change[Key, Value], *table.Change[Key, Value]],
+	error,
+) {
+	setRow, err := t.Setter(t.Columns()...)
+	if err != nil {
+		return nil, err
+	}
+	deleteRow := t.Deleter()
+
+	apply := func(c *table.Change[Key, Value]) error {
+		switch c.Operation {
+		case table.Inserted, table.Updated:
+			return setRow(c.Key, c.New...)
+		default:
+			return deleteRow(c.Key)
+		}
+	}
+
+	return func(
+		c *context.Context[*table.Change[Key, Value], *table.Change[Key, Value]],
+	) {
+		for {
+			if change, ok := c.FetchMessage(); !ok {
+				return
+			} else if e := apply(change); e != nil {
+				if !c.Error(e) {
+					return
+				}
+			} else if !c.ForwardResult(change) {
+				return
+			}
+		}
+	}, nil
+}
+
+// TableRow is a row of a Table, as forwarded by TableScan and
+// TableChangedScan. Values holds the scanned columns in the order requested
+type TableRow[Key comparable, Value any] struct {
+	Key    Key
+	Values []Value
+}
+
+// TableScan constructs a processor that scans the provided Table every time
+// the trigger channel delivers, and forwards each of its rows. Scheduled scans
+// can be triggered by a time.Ticker's channel, and on-demand scans by sending
+// to a channel of your own. Each scan visits a consistent point-in-time
+// snapshot of the Table's rows. If a RowPredicate is provided, only the rows
+// it matches are forwarded. The processor stops once the trigger channel is
+// closed
+func TableScan[Key comparable, Value any, Trigger any](
+	t table.Table[Key, Value],
+	trigger <-chan Trigger,
+	where table.RowPredicate[Key, Value],
+	c ...table.ColumnName,
+) (stream.Processor[stream.Source, *TableRow[Key, Value]], error) {
+	scan, err := t.Scanner(c...)
+	if err != nil {
+		return nil, err
+	}
+	if where != nil {
+		scan = scan.Where(where)
+	}
+	return scanOn(trigger, func() table.Scanner[Key, Value] {
+		return scan
+	}), nil
+}
+
+// TableChangedScan constructs a processor that scans the provided Table like
+// TableScan, but only forwards the rows that have been inserted or updated
+// since the previous scan. The first scan forwards every row. A row that
+// changes while a scan is in progress may be forwarded again by the next one,
+// but a change is never missed
+func TableChangedScan[Key comparable, Value any, Trigger any](
+	t table.Table[Key, Value],
+	trigger <-chan Trigger,
+	where table.RowPredicate[Key, Value],
+	c ...table.ColumnName,
+) (stream.Processor[stream.Source, *TableRow[Key, Value]], error) {
+	scan, err := t.Scanner(c...)
+	if err != nil {
+		return nil, err
+	}
+	getVersion, err := t.VersionedGetter()
+	if err != nil {
+		return nil, err
+	}
+	return scanOn(trigger, func() table.Scanner[Key, Value] {
+		// Each start of the processor forwards every row on its first scan
+		last := map[Key]table.Version{}
+		return func(visit table.Visitor[Key, Value]) error {
+			// Versions are read before the rows, so a recorded Version is
+			// never newer than the Values that were forwarded for it
+			keys, err := t.Keys()
+			if err != nil {
+				return err
+			}
+			current := make(map[Key]table.Version, len(keys))
+			for _, k := range keys {
+				if _, v, err := getVersion(k); err == nil {
+					current[k] = v
+				}
+			}
+			next := make(map[Key]table.Version, len(current))
+			if err := scan(func(k Key, v []Value) bool {
+				ver, ok := current[k]
+				if ok && ver == last[k] {
+					return true
+				}
+				if where == nil || where(k, v) {
+					if !visit(k, v) {
+						return false
+					}
+				}
+				if ok {
+					next[k] = ver
+				}
+				return true
+			}); err != nil {
+				// Nothing is recorded, so the next scan tries again
+				return err
+			}
+			// Rows that are unchanged, or that weren't reached because the
+			// scan stopped early, keep the Version they were last seen at
+			for k, ver := range last {
+				if _, ok := next[k]; !ok {
+					if _, ok := current[k]; ok {
+						next[k] = ver
+					}
+				}
+			}
+			last = next
+			return nil
+		}
+	}), nil
+}
+
+// scanOn constructs a processor that scans every time the trigger channel
+// delivers. The Scanner is made each time the processor starts, so that any
+// state it keeps between scans belongs to that start alone
+func scanOn[Key comparable, Value any, Trigger any](
+	trigger <-chan Trigger, makeScan func() table.Scanner[Key, Value],
+) stream.Processor[stream.Source, *TableRow[Key, Value]] {
+	return func(c *context.Context[stream.Source, *TableRow[Key, Value]]) {
+		scan := makeScan()
+		for {
+			if _, ok := c.FetchMessage(); !ok {
+				return
+			}
+			select {
+			case <-c.Done:
+				return
+			case _, ok := <-trigger:
+				if !ok {
+					return
+				}
+			}
+			done := false
+			if err := scan(func(k Key, v []Value) bool {
+				done = !c.ForwardResult(&TableRow[Key, Value]{
+					Key:    k,
+					Values: v,
+				})
+				return !done
+			}); err != nil {
+				done = !c.Error(err)
+			}
+			if done {
+				return
+			}
+		}
+	}
+}
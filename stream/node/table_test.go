@@ -3,12 +3,15 @@ package node_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/caravan/streaming"
+	"github.com/caravan/streaming/stream"
 	"github.com/caravan/streaming/stream/context"
 	"github.com/caravan/streaming/stream/node"
 	"github.com/caravan/streaming/table"
 	"github.com/caravan/streaming/table/column"
+	"github.com/caravan/streaming/table/config"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -139,3 +142,414 @@ func TestLookupProcessError(t *testing.T) {
 	)
 	close(done)
 }
+
+func TestTableDeleter(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, updater := makeTestTable()
+	as.Nil(updater.Update(&row{
+		id:    "some id",
+		name:  "some name",
+		value: "some value",
+	}))
+
+	deleter := node.TableDeleter(tbl, func(k string) string {
+		return k
+	})
+	getter, _ := tbl.Getter("value")
+
+	done := make(chan context.Done)
+	in := make(chan string)
+	out := make(chan string)
+	monitor := make(chan context.Advice)
+
+	deleter.Start(context.Make(done, monitor, in, out))
+	in <- "some id"
+	as.Equal("some id", <-out)
+
+	res, err := getter("some id")
+	as.Nil(res)
+	as.EqualError(err, fmt.Sprintf(table.ErrKeyNotFound, "some id"))
+
+	// Deleting it again does nothing, and still forwards the message
+	in <- "some id"
+	as.Equal("some id", <-out)
+	close(done)
+}
+
+func TestTableChanges(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, updater := makeTestTable()
+	changes := node.TableChanges(tbl)
+
+	done := make(chan context.Done)
+	in := make(chan stream.Source)
+	out := make(chan *table.Change[string, string])
+
+	changes.Start(context.Make(done, make(chan context.Advice), in, out))
+
+	// The processor watches the Table before it fetches its first message
+	in <- stream.Source{}
+	as.Nil(updater.Update(&row{
+		id:    "some id",
+		name:  "some name",
+		value: "some value",
+	}))
+	as.Nil(tbl.Deleter()("some id"))
+	as.Equal(&table.Change[string, string]{
+		Operation: table.Inserted,
+		Key:       "some id",
+		New:       []string{"some id", "some name", "some value"},
+	}, <-out)
+
+	in <- stream.Source{}
+	as.Equal(&table.Change[string, string]{
+		Operation: table.Deleted,
+		Key:       "some id",
+		Old:       []string{"some id", "some name", "some value"},
+	}, <-out)
+	close(done)
+
+	// Another start of the processor watches the Table on its own
+	done = make(chan context.Done)
+	in = make(chan stream.Source)
+	out = make(chan *table.Change[string, string])
+	changes.Start(context.Make(done, make(chan context.Advice), in, out))
+	in <- stream.Source{}
+	as.Nil(updater.Update(&row{
+		id:    "other id",
+		name:  "other name",
+		value: "other value",
+	}))
+	as.Equal(table.Inserted, (<-out).Operation)
+	close(done)
+}
+
+func TestTableReplicator(t *testing.T) {
+	as := assert.New(t)
+
+	source, updater := makeTestTable()
+	replica, _ := makeTestTable()
+
+	replicator, err := node.TableReplicator(replica)
+	as.NotNil(replicator)
+	as.Nil(err)
+
+	done := make(chan context.Done)
+	in := make(chan stream.Source)
+	out := make(chan *table.Change[string, string])
+
+	node.Bind(node.TableChanges(source), replicator).Start(
+		context.Make(done, make(chan context.Advice), in, out),
+	)
+
+	in <- stream.Source{}
+	as.Nil(updater.Update(&row{
+		id:    "some id",
+		name:  "some name",
+		value: "some value",
+	}))
+	as.Nil(source.Deleter()("some id"))
+	as.Nil(updater.Update(&row{
+		id:    "other id",
+		name:  "other name",
+		value: "other value",
+	}))
+	as.Equal(table.Inserted, (<-out).Operation)
+	getter, _ := replica.Getter("name")
+	res, _ := getter("some id")
+	as.Equal([]string{"some name"}, res)
+
+	in <- stream.Source{}
+	as.Equal(table.Deleted, (<-out).Operation)
+	_, err = getter("some id")
+	as.EqualError(err, fmt.Sprintf(table.ErrKeyNotFound, "some id"))
+
+	in <- stream.Source{}
+	as.Equal(table.Inserted, (<-out).Operation)
+	res, _ = getter("other id")
+	as.Equal([]string{"other name"}, res)
+	close(done)
+}
+
+func TestTableLookupBy(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := streaming.NewTableWith[string, string](
+		[]table.ColumnName{"id", "name", "value"},
+		config.Index("name"),
+	)
+	setRow, _ := tbl.Setter("name", "value")
+	as.Nil(setRow("1", "some name", "first value"))
+	as.Nil(setRow("2", "some name", "second value"))
+	as.Nil(setRow("3", "other name", "third value"))
+
+	lookup, err := node.TableLookupBy(tbl, "name", "value",
+		func(n string) string {
+			return n
+		},
+	)
+	as.NotNil(lookup)
+	as.Nil(err)
+
+	done := make(chan context.Done)
+	in := make(chan string)
+	out := make(chan string)
+	monitor := make(chan context.Advice)
+
+	lookup.Start(context.Make(done, monitor, in, out))
+	in <- "some name"
+	as.ElementsMatch(
+		[]string{"first value", "second value"},
+		[]string{<-out, <-out},
+	)
+	in <- "missing name"
+	as.EqualError(
+		(<-monitor).(error),
+		fmt.Sprintf(table.ErrIndexValueNotFound, "missing name"),
+	)
+	in <- "other name"
+	as.Equal("third value", <-out)
+	close(done)
+
+	lookup, err = node.TableLookupBy(tbl, "value", "name",
+		func(n string) string {
+			return n
+		},
+	)
+	as.Nil(lookup)
+	as.EqualError(err, fmt.Sprintf(table.ErrIndexNotFound, []string{"value"}))
+}
+
+type account struct {
+	ID      string `table:"-"`
+	Owner   string `table:"owner"`
+	Balance int    `table:"balance"`
+}
+
+func TestTableFieldLookup(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, _ := streaming.NewStructTable[string, account]()
+	as.Nil(tbl.Put("1", account{Owner: "bob", Balance: 42}))
+
+	balance, _ := column.Field[account, int]("balance")
+	lookup, err := node.TableFieldLookup(tbl, balance,
+		func(k string) string {
+			return k
+		},
+	)
+	as.NotNil(lookup)
+	as.Nil(err)
+
+	done := make(chan context.Done)
+	in := make(chan string)
+	out := make(chan int)
+	monitor := make(chan context.Advice)
+
+	lookup.Start(context.Make(done, monitor, in, out))
+	in <- "1"
+	as.Equal(42, <-out)
+	in <- "2"
+	as.EqualError(
+		(<-monitor).(error), fmt.Sprintf(table.ErrKeyNotFound, "2"),
+	)
+	close(done)
+}
+
+func TestTableBatchLookup(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, updater := makeTestTable()
+	for i := 0; i < 3; i++ {
+		as.Nil(updater.Update(&row{
+			id:    fmt.Sprint(i),
+			value: fmt.Sprintf("value %d", i),
+		}))
+	}
+
+	lookup, err := node.TableBatchLookup(tbl, "value",
+		func(k string) string {
+			return k
+		}, 8,
+	)
+	as.NotNil(lookup)
+	as.Nil(err)
+
+	done := make(chan context.Done)
+	in := make(chan string, 8)
+	out := make(chan string)
+	monitor := make(chan context.Advice)
+
+	// Messages that are already waiting are looked up together
+	in <- "2"
+	in <- "missing"
+	in <- "0"
+	lookup.Start(context.Make(done, monitor, in, out))
+	as.Equal("value 2", <-out)
+	as.EqualError(
+		(<-monitor).(error), fmt.Sprintf(table.ErrKeyNotFound, "missing"),
+	)
+	as.Equal("value 0", <-out)
+
+	in <- "1"
+	as.Equal("value 1", <-out)
+	close(done)
+
+	// Each Start of the Processor batches on its own
+	done = make(chan context.Done)
+	lookup.Start(context.Make(done, monitor, in, out))
+	lookup.Start(context.Make(done, monitor, in, out))
+	go func() {
+		for i := 0; i < 100; i++ {
+			in <- fmt.Sprint(i % 3)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		as.Contains([]string{"value 0", "value 1", "value 2"}, <-out)
+	}
+	close(done)
+
+	lookup, err = node.TableBatchLookup(tbl, "value",
+		func(k string) string {
+			return k
+		}, 0,
+	)
+	as.Nil(lookup)
+	as.EqualError(err, fmt.Sprintf(node.ErrInvalidBatchSize, 0))
+
+	lookup, err = node.TableBatchLookup(tbl, "missing",
+		func(k string) string {
+			return k
+		}, 8,
+	)
+	as.Nil(lookup)
+	as.EqualError(err, fmt.Sprintf(table.ErrColumnNotFound, "missing"))
+}
+
+func receiveRows(
+	out <-chan *node.TableRow[string, string], n int,
+) map[string][]string {
+	res := map[string][]string{}
+	for i := 0; i < n; i++ {
+		r := <-out
+		res[r.Key] = r.Values
+	}
+	return res
+}
+
+func TestTableScan(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, updater := makeTestTable()
+	for _, id := range []string{"1", "2", "3"} {
+		as.Nil(updater.Update(&row{id: id, name: "name " + id, value: id}))
+	}
+
+	trigger := make(chan struct{})
+	scan, err := node.TableScan(tbl, trigger,
+		func(k string, _ []string) bool {
+			return k != "2"
+		},
+		"name",
+	)
+	as.Nil(err)
+
+	done := make(chan context.Done)
+	in := make(chan stream.Source)
+	out := make(chan *node.TableRow[string, string])
+	scan.Start(context.Make(done, make(chan context.Advice), in, out))
+
+	for i := 0; i < 2; i++ {
+		in <- stream.Source{}
+		trigger <- struct{}{}
+		as.Equal(map[string][]string{
+			"1": {"name 1"},
+			"3": {"name 3"},
+		}, receiveRows(out, 2))
+	}
+
+	// Closing the trigger stops the processor
+	close(trigger)
+	in <- stream.Source{}
+	select {
+	case in <- stream.Source{}:
+		as.Fail("processor should have stopped")
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(done)
+
+	_, err = node.TableScan[string, string, struct{}](tbl, nil, nil, "missing")
+	as.EqualError(err, fmt.Sprintf(table.ErrColumnNotFound, "missing"))
+}
+
+func TestTableChangedScan(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, updater := makeTestTable()
+	for _, id := range []string{"1", "2", "3"} {
+		as.Nil(updater.Update(&row{id: id, name: "name " + id, value: id}))
+	}
+
+	trigger := make(chan time.Time)
+	scan, err := node.TableChangedScan(tbl, trigger,
+		func(_ string, v []string) bool {
+			return v[1] != "skip"
+		},
+		"name", "value",
+	)
+	as.Nil(err)
+
+	done := make(chan context.Done)
+	in := make(chan stream.Source)
+	out := make(chan *node.TableRow[string, string])
+	scan.Start(context.Make(done, make(chan context.Advice), in, out))
+
+	in <- stream.Source{}
+	trigger <- time.Now()
+	as.Equal(map[string][]string{
+		"1": {"name 1", "1"},
+		"2": {"name 2", "2"},
+		"3": {"name 3", "3"},
+	}, receiveRows(out, 3))
+
+	as.Nil(updater.Update(&row{id: "2", name: "renamed", value: "2"}))
+	as.Nil(updater.Update(&row{id: "3", name: "name 3", value: "skip"}))
+	as.Nil(updater.Update(&row{id: "4", name: "name 4", value: "4"}))
+	as.Nil(tbl.Deleter()("1"))
+	as.Nil(updater.Update(&row{id: "1", name: "name 1", value: "1"}))
+
+	in <- stream.Source{}
+	trigger <- time.Now()
+	as.Equal(map[string][]string{
+		"1": {"name 1", "1"},
+		"2": {"renamed", "2"},
+		"4": {"name 4", "4"},
+	}, receiveRows(out, 3))
+
+	// Nothing has changed, so the next change is the first row forwarded.
+	// The processor only fetches its next message once a scan is complete
+	in <- stream.Source{}
+	trigger <- time.Now()
+	in <- stream.Source{}
+	as.Nil(updater.Update(&row{id: "4", name: "name 4", value: "four"}))
+	trigger <- time.Now()
+	as.Equal(&node.TableRow[string, string]{
+		Key:    "4",
+		Values: []string{"name 4", "four"},
+	}, <-out)
+	close(done)
+
+	// Another start of the processor has seen nothing yet
+	done = make(chan context.Done)
+	scan.Start(context.Make(done, make(chan context.Advice), in, out))
+	in <- stream.Source{}
+	trigger <- time.Now()
+	as.Equal(map[string][]string{
+		"1": {"name 1", "1"},
+		"2": {"renamed", "2"},
+		"4": {"name 4", "four"},
+	}, receiveRows(out, 3))
+	close(done)
+}
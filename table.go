@@ -1,7 +1,9 @@
 package streaming
 
 import (
+	"github.com/caravan/essentials/topic"
 	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/config"
 
 	internal "github.com/caravan/streaming/internal/table"
 )
@@ -13,6 +15,38 @@ func NewTable[Key comparable, Value any](
 	return internal.Make[Key, Value](c...)
 }
 
+// NewTableWith instantiates a new Table given a set of column names and the
+// Options used to configure it
+func NewTableWith[Key comparable, Value any](
+	c []table.ColumnName, o ...config.Option,
+) (table.Table[Key, Value], error) {
+	return internal.MakeWith[Key, Value](c, o...)
+}
+
+// NewOrderedTable instantiates a new OrderedTable given a set of column names
+func NewOrderedTable[Key table.Ordered, Value any](
+	c ...table.ColumnName,
+) (table.OrderedTable[Key, Value], error) {
+	return internal.MakeOrdered[Key, Value](c)
+}
+
+// NewOrderedTableWith instantiates a new OrderedTable given a set of column
+// names and the Options used to configure it
+func NewOrderedTableWith[Key table.Ordered, Value any](
+	c []table.ColumnName, o ...config.Option,
+) (table.OrderedTable[Key, Value], error) {
+	return internal.MakeOrdered[Key, Value](c, o...)
+}
+
+// NewStructTable instantiates a new StructTable whose Columns are derived from
+// the fields of the struct type Row, applying the provided Options to its
+// configuration
+func NewStructTable[Key comparable, Row any](
+	o ...config.Option,
+) (table.StructTable[Key, Row], error) {
+	return internal.MakeStruct[Key, Row](o...)
+}
+
 // NewTableUpdater instantiates a new table Updater given a Table and a set of
 // Key and Column Selectors
 func NewTableUpdater[Msg any, Key comparable, Value any](
@@ -22,3 +56,57 @@ func NewTableUpdater[Msg any, Key comparable, Value any](
 ) (table.Updater[Msg, Key, Value], error) {
 	return internal.MakeUpdater[Msg, Key, Value](t, k, c...)
 }
+
+// NewTableTombstoneUpdater instantiates a new table Updater given a Table, a
+// Key Selector, a Tombstone for identifying deletions, and a set of Column
+// Selectors
+func NewTableTombstoneUpdater[Msg any, Key comparable, Value any](
+	t table.Table[Key, Value],
+	k table.KeySelector[Msg, Key],
+	d table.Tombstone[Msg],
+	c ...table.Column[Msg, Value],
+) (table.Updater[Msg, Key, Value], error) {
+	return internal.MakeTombstoneUpdater[Msg, Key, Value](t, k, d, c...)
+}
+
+// NewKeyJoin instantiates a View that joins the rows of two Tables sharing the
+// same Key, holding the specified left columns followed by the specified
+// right columns. The Options configure the View's underlying Table
+func NewKeyJoin[Key comparable, Value any](
+	left, right table.Table[Key, Value], kind table.JoinType,
+	lc, rc []table.ColumnName, o ...config.Option,
+) (table.View[Key, Value], error) {
+	return internal.MakeKeyJoin[Key, Value](left, right, kind, lc, rc, o...)
+}
+
+// NewForeignKeyJoin instantiates a View that joins each row of the left Table
+// with the row of the right Table whose Key is held in the left row's foreign
+// Key column. The View holds the specified left columns followed by the
+// specified right columns, and the Options configure its underlying Table
+func NewForeignKeyJoin[Key, Foreign comparable, Value any](
+	left table.Table[Key, Value], fk table.ColumnName,
+	right table.Table[Foreign, Value], kind table.JoinType,
+	lc, rc []table.ColumnName, o ...config.Option,
+) (table.View[Key, Value], error) {
+	return internal.MakeForeignKeyJoin[Key, Foreign, Value](
+		left, fk, right, kind, lc, rc, o...,
+	)
+}
+
+// NewGlobalTable instantiates a View that applies every message of the Topic
+// to the Table using the provided Updater, which must update that Table. It
+// returns once the messages already in the Topic have been applied, so that
+// Streams started afterward never see a partially populated Table. The Topic
+// must retain every message it's ever been sent, so a Topic with a counted or
+// timed retention Policy isn't suitable. If one of those messages can't be
+// applied, no View is returned, and the Table keeps the rows written by the
+// messages before it. Messages produced afterward are applied in the
+// background, and one that can't be applied stops the View, which reports why
+// through Err. Closing the View also closes the Table
+func NewGlobalTable[Msg any, Key comparable, Value any](
+	t table.Table[Key, Value],
+	top topic.Topic[Msg],
+	u table.Updater[Msg, Key, Value],
+) (table.View[Key, Value], error) {
+	return internal.MakeGlobal[Msg, Key, Value](t, top, u)
+}
@@ -0,0 +1,41 @@
+package table
+
+type (
+	// Change describes a modification made to a single row of a Table. Old
+	// holds the row's column Values before the modification, and New holds
+	// them after. Old is nil for Inserted rows, and New is nil for Deleted,
+	// Expired, or Evicted rows
+	Change[Key comparable, Value any] struct {
+		Operation Operation
+		Key       Key
+		Old       []Value
+		New       []Value
+	}
+
+	// Operation identifies the kind of modification described by a Change
+	Operation uint8
+)
+
+// Operations
+const (
+	Inserted Operation = iota
+	Updated
+	Deleted
+	Expired
+	Evicted
+)
+
+var operationNames = map[Operation]string{
+	Inserted: "inserted",
+	Updated:  "updated",
+	Deleted:  "deleted",
+	Expired:  "expired",
+	Evicted:  "evicted",
+}
+
+func (o Operation) String() string {
+	if n, ok := operationNames[o]; ok {
+		return n
+	}
+	return "unknown"
+}
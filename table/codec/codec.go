@@ -0,0 +1,56 @@
+package codec
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"io"
+)
+
+type (
+	// Codec creates the Encoders and Decoders used to persist Table rows
+	Codec interface {
+		NewEncoder(io.Writer) Encoder
+		NewDecoder(io.Reader) Decoder
+	}
+
+	// Encoder writes a sequence of values to an underlying stream
+	Encoder interface {
+		Encode(any) error
+	}
+
+	// Decoder reads a sequence of values from an underlying stream
+	Decoder interface {
+		Decode(any) error
+	}
+
+	gobCodec  struct{}
+	jsonCodec struct{}
+)
+
+var (
+	// Gob is a Codec that uses encoding/gob. If a Table's Value is an
+	// interface type, the concrete types stored in it must be registered
+	// using gob.Register
+	Gob Codec = gobCodec{}
+
+	// JSON is a Codec that uses encoding/json. If a Table's Value is an
+	// interface type, Values are restored as the types that encoding/json
+	// chooses for them, such as float64 for all numbers
+	JSON Codec = jsonCodec{}
+)
+
+func (gobCodec) NewEncoder(w io.Writer) Encoder {
+	return gob.NewEncoder(w)
+}
+
+func (gobCodec) NewDecoder(r io.Reader) Decoder {
+	return gob.NewDecoder(r)
+}
+
+func (jsonCodec) NewEncoder(w io.Writer) Encoder {
+	return json.NewEncoder(w)
+}
+
+func (jsonCodec) NewDecoder(r io.Reader) Decoder {
+	return json.NewDecoder(r)
+}
@@ -0,0 +1,33 @@
+package codec_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/caravan/streaming/table/codec"
+	"github.com/stretchr/testify/assert"
+)
+
+type record struct {
+	Name string
+	Age  int
+}
+
+func TestCodecs(t *testing.T) {
+	for _, c := range []codec.Codec{codec.Gob, codec.JSON} {
+		as := assert.New(t)
+
+		var buf bytes.Buffer
+		enc := c.NewEncoder(&buf)
+		as.Nil(enc.Encode(&record{Name: "bob", Age: 42}))
+		as.Nil(enc.Encode(&record{Name: "june", Age: 36}))
+
+		dec := c.NewDecoder(&buf)
+		var res record
+		as.Nil(dec.Decode(&res))
+		as.Equal(record{Name: "bob", Age: 42}, res)
+		as.Nil(dec.Decode(&res))
+		as.Equal(record{Name: "june", Age: 36}, res)
+		as.NotNil(dec.Decode(&res))
+	}
+}
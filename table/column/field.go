@@ -0,0 +1,163 @@
+package column
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/caravan/streaming/table"
+)
+
+type (
+	// field is a Column backed by a field of a struct
+	field[Row, T any] struct {
+		name    table.ColumnName
+		index   []int
+		typ     reflect.Type
+		pointer bool
+	}
+
+	// structField describes a field of a struct that becomes a Column
+	structField struct {
+		name  table.ColumnName
+		index []int
+		typ   reflect.Type
+	}
+)
+
+// tagName is the struct tag that renames a field's Column. A tag of "-" keeps
+// the field from becoming a Column at all
+const tagName = "table"
+
+// Field returns the Field of the struct type Row that's named by the provided
+// ColumnName. The field's Values must be of type T, or T must be an interface
+// that they implement
+func Field[Row, T any](n table.ColumnName) (table.Field[Row, T], error) {
+	fields, pointer, err := structFields[Row]()
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range fields {
+		if f.name != n {
+			continue
+		}
+		want := reflect.TypeOf((*T)(nil)).Elem()
+		if f.typ != want &&
+			(want.Kind() != reflect.Interface || !f.typ.Implements(want)) {
+			return nil, fmt.Errorf(table.ErrFieldType, n, f.typ, want)
+		}
+		return makeField[Row, T](f, pointer), nil
+	}
+	return nil, fmt.Errorf(table.ErrFieldNotFound, n)
+}
+
+// Fields returns a Column for every field of the struct type Row, in the
+// order they're declared. Each is also a table.Field with Values of type any
+func Fields[Row any]() ([]table.Column[Row, any], error) {
+	fields, pointer, err := structFields[Row]()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]table.Column[Row, any], len(fields))
+	for i, f := range fields {
+		res[i] = makeField[Row, any](f, pointer)
+	}
+	return res, nil
+}
+
+func makeField[Row, T any](f *structField, pointer bool) *field[Row, T] {
+	return &field[Row, T]{
+		name:    f.name,
+		index:   f.index,
+		typ:     f.typ,
+		pointer: pointer,
+	}
+}
+
+// structFields returns the fields of the struct type Row that become Columns,
+// and whether Row is a pointer to that struct
+func structFields[Row any]() ([]*structField, bool, error) {
+	t := reflect.TypeOf((*Row)(nil)).Elem()
+	pointer := t.Kind() == reflect.Pointer
+	if pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, false, fmt.Errorf(
+			table.ErrStructRequired, reflect.TypeOf((*Row)(nil)).Elem(),
+		)
+	}
+	var res []*structField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := table.ColumnName(f.Name)
+		if tag, ok := f.Tag.Lookup(tagName); ok {
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = table.ColumnName(tag)
+			}
+		}
+		res = append(res, &structField{
+			name:  name,
+			index: f.Index,
+			typ:   f.Type,
+		})
+	}
+	return res, pointer, nil
+}
+
+func (f *field[_, _]) Name() table.ColumnName {
+	return f.name
+}
+
+func (f *field[Row, _]) Select(r Row) any {
+	if v, ok := f.value(r); ok {
+		return v.Interface()
+	}
+	return reflect.Zero(f.typ).Interface()
+}
+
+func (f *field[Row, T]) Value(r Row) T {
+	var res T
+	if v, ok := f.value(r); ok {
+		// A nil interface field leaves the zero Value
+		res, _ = v.Interface().(T)
+	}
+	return res
+}
+
+func (f *field[Row, _]) value(r Row) (reflect.Value, bool) {
+	v := reflect.ValueOf(&r).Elem()
+	if f.pointer {
+		if v.IsNil() {
+			return reflect.Value{}, false
+		}
+		v = v.Elem()
+	}
+	return v.FieldByIndex(f.index), true
+}
+
+func (f *field[Row, _]) Assign(r *Row, value any) error {
+	v := reflect.ValueOf(r).Elem()
+	if f.pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	dst := v.FieldByIndex(f.index)
+	if value == nil {
+		dst.Set(reflect.Zero(f.typ))
+		return nil
+	}
+	src := reflect.ValueOf(value)
+	if !src.Type().AssignableTo(f.typ) {
+		return fmt.Errorf(table.ErrFieldValue, value, f.name)
+	}
+	dst.Set(src)
+	return nil
+}
@@ -0,0 +1,94 @@
+package column_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/column"
+	"github.com/stretchr/testify/assert"
+)
+
+type person struct {
+	Name    string `table:"name"`
+	Age     int    `table:"age"`
+	Email   string
+	Secret  string `table:"-"`
+	Tags    fmt.Stringer
+	private int
+}
+
+func TestField(t *testing.T) {
+	as := assert.New(t)
+
+	age, err := column.Field[person, int]("age")
+	as.Nil(err)
+	as.Equal(table.ColumnName("age"), age.Name())
+
+	p := person{Name: "bob", Age: 42}
+	as.Equal(42, age.Value(p))
+	as.Equal(42, age.Select(p))
+
+	// Value is a ValueSelector, so it can be used to make a Column
+	c := column.Make("years", age.Value)
+	as.Equal(43, c.Select(person{Age: 43}))
+
+	as.Nil(age.Assign(&p, 36))
+	as.Equal(36, p.Age)
+	as.Nil(age.Assign(&p, nil))
+	as.Equal(0, p.Age)
+	as.EqualError(age.Assign(&p, "old"),
+		fmt.Sprintf(table.ErrFieldValue, "old", "age"),
+	)
+
+	tags, err := column.Field[person, fmt.Stringer]("Tags")
+	as.Nil(err)
+	as.Nil(tags.Value(p))
+}
+
+func TestPointerField(t *testing.T) {
+	as := assert.New(t)
+
+	name, err := column.Field[*person, string]("name")
+	as.Nil(err)
+	as.Equal("bob", name.Value(&person{Name: "bob"}))
+	as.Equal("", name.Value(nil))
+	as.Equal("", name.Select(nil))
+
+	var p *person
+	as.Nil(name.Assign(&p, "june"))
+	as.Equal(&person{Name: "june"}, p)
+}
+
+func TestFields(t *testing.T) {
+	as := assert.New(t)
+
+	cols, err := column.Fields[person]()
+	as.Nil(err)
+	names := make([]table.ColumnName, len(cols))
+	for i, c := range cols {
+		names[i] = c.Name()
+	}
+	as.Equal([]table.ColumnName{"name", "age", "Email", "Tags"}, names)
+	as.Equal("bob", cols[0].Select(person{Name: "bob"}))
+}
+
+func TestFieldErrors(t *testing.T) {
+	as := assert.New(t)
+
+	f, err := column.Field[person, string]("age")
+	as.Nil(f)
+	as.EqualError(err, fmt.Sprintf(table.ErrFieldType, "age", "int", "string"))
+
+	f, err = column.Field[person, string]("Secret")
+	as.Nil(f)
+	as.EqualError(err, fmt.Sprintf(table.ErrFieldNotFound, "Secret"))
+
+	s, err := column.Field[string, string]("name")
+	as.Nil(s)
+	as.EqualError(err, fmt.Sprintf(table.ErrStructRequired, "string"))
+
+	cols, err := column.Fields[*int]()
+	as.Nil(cols)
+	as.EqualError(err, fmt.Sprintf(table.ErrStructRequired, "*int"))
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/caravan/essentials/topic"
+	"github.com/caravan/streaming/table"
+)
+
+// Error messages
+const (
+	ErrChangelogAlreadySet = "changelog already set in table"
+	ErrChangelogType       = "changelog type does not match table: %T"
+)
+
+// Changelog configures a Table to append every Change made to it to the
+// provided Topic. When the Table is constructed, it is rebuilt by replaying
+// the Changes already in the Topic, keeping only the last Change for each
+// Key. The Topic must retain every message it's ever been sent, as the
+// replay consumes exactly as many messages as the Topic's Length
+func Changelog[Key comparable, Value any](
+	t topic.Topic[*table.Change[Key, Value]],
+) Option {
+	return func(c *Config) error {
+		if c.Changelog != nil || c.ChangelogFile != "" {
+			return errors.New(ErrChangelogAlreadySet)
+		}
+		c.Changelog = t
+		return nil
+	}
+}
+
+// ChangelogFile configures a Table to append every Change made to it to the
+// specified local file, using the Table's Codec. When the Table is
+// constructed, it is rebuilt by replaying the file, keeping only the last
+// Change for each Key, and the file is rewritten in that compacted form
+func ChangelogFile(path string) Option {
+	return func(c *Config) error {
+		if c.Changelog != nil || c.ChangelogFile != "" {
+			return errors.New(ErrChangelogAlreadySet)
+		}
+		c.ChangelogFile = path
+		return nil
+	}
+}
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/caravan/streaming/table/codec"
+)
+
+// Error messages
+const (
+	ErrCodecAlreadySet = "codec already set in table"
+)
+
+// Codec configures the Codec a Table uses when persisting its rows to files,
+// such as snapshots and changelogs. The default is codec.Gob
+func Codec(cc codec.Codec) Option {
+	return func(c *Config) error {
+		if c.Codec != nil {
+			return errors.New(ErrCodecAlreadySet)
+		}
+		c.Codec = cc
+		return nil
+	}
+}
@@ -0,0 +1,20 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/caravan/streaming"
+	"github.com/caravan/streaming/table/codec"
+	"github.com/caravan/streaming/table/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodecConflict(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := streaming.NewTableWith[string, any](columns,
+		config.Codec(codec.Gob), config.Codec(codec.JSON),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, config.ErrCodecAlreadySet)
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/caravan/streaming/table"
+)
+
+// Error messages
+const (
+	ErrColumnDefaultAlreadySet = "default already set for column: %s"
+	ErrColumnDefaultType       = "default for column %s is of the wrong type: %T"
+	ErrMergeAlreadySet         = "merge already set for column: %s"
+	ErrMergeType               = "merge for column %s is of the wrong type: %T"
+)
+
+// ColumnDefault declares the Value that a column of a Table holds until it's
+// set. It's used in place of the zero Value when a Setter creates a row
+// without setting the column. A column that holds its default is still
+// reported as unset by a PresenceGetter
+func ColumnDefault[Value any](col table.ColumnName, v Value) Option {
+	return func(c *Config) error {
+		if _, ok := c.ColumnDefaults[col]; ok {
+			return fmt.Errorf(ErrColumnDefaultAlreadySet, col)
+		}
+		if c.ColumnDefaults == nil {
+			c.ColumnDefaults = map[table.ColumnName]any{}
+		}
+		c.ColumnDefaults[col] = v
+		return nil
+	}
+}
+
+// Merge declares a Reducer for a column of a Table. Once the column has been
+// set in a row, every Value written to it is merged with the one it holds
+// rather than replacing it. Reducers are applied by Setters, Updaters, and
+// Transactions alike, while the Table's lock is held
+func Merge[Value any](col table.ColumnName, r table.Reducer[Value]) Option {
+	return func(c *Config) error {
+		if _, ok := c.Merges[col]; ok {
+			return fmt.Errorf(ErrMergeAlreadySet, col)
+		}
+		if c.Merges == nil {
+			c.Merges = map[table.ColumnName]any{}
+		}
+		c.Merges[col] = r
+		return nil
+	}
+}
@@ -0,0 +1,47 @@
+package config
+
+import (
+	"time"
+
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/codec"
+	"github.com/caravan/streaming/table/store"
+)
+
+type (
+	// Config conveys the properties of a Table that one can configure using
+	// Options
+	Config struct {
+		TTL           time.Duration
+		SweepInterval time.Duration
+		OnExpire      any
+
+		SnapshotFile     string
+		SnapshotInterval time.Duration
+
+		Changelog     any
+		ChangelogFile string
+
+		Indexes        [][]table.ColumnName
+		ColumnDefaults map[table.ColumnName]any
+		Merges         map[table.ColumnName]any
+
+		Shards int
+		Store  any
+
+		MaxRows   int
+		MaxMemory int64
+		Eviction  store.Policy
+		OnEvict   any
+
+		Codec codec.Codec
+	}
+
+	// Option applies an option to a table configuration instance
+	Option func(*Config) error
+)
+
+// Defaults
+const (
+	DefaultSweepInterval = time.Second
+)
@@ -0,0 +1,35 @@
+package config
+
+import "github.com/caravan/streaming/table/codec"
+
+// ApplyDefaults copies a Config instance and applies defaults to it
+func ApplyDefaults(c *Config) *Config {
+	res := *c
+	if res.SweepInterval == 0 {
+		res.SweepInterval = DefaultSweepInterval
+		if res.TTL != 0 && res.TTL < res.SweepInterval {
+			res.SweepInterval = res.TTL
+		}
+	}
+	if res.Codec == nil {
+		res.Codec = codec.Gob
+	}
+	return &res
+}
+
+// ApplyOptions applies Options to a table
+func ApplyOptions(c *Config, options ...Option) error {
+	for _, o := range options {
+		if err := o(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Defaults applies default settings to a table if they weren't explicitly set
+// through some other Option
+func Defaults(c *Config) error {
+	*c = *ApplyDefaults(c)
+	return nil
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/caravan/streaming/table/store"
+)
+
+// EvictHandler is called with the Key and full set of column Values for each
+// row that a Table evicts to stay within its bounds
+type EvictHandler[Key comparable, Value any] func(Key, []Value)
+
+// Error messages
+const (
+	ErrMaxRowsAlreadySet   = "max rows already set in table"
+	ErrMaxMemoryAlreadySet = "max memory already set in table"
+	ErrEvictionAlreadySet  = "eviction policy already set in table"
+	ErrOnEvictAlreadySet   = "evict handler already set in table"
+	ErrInvalidMaxRows      = "max rows must be greater than zero: %d"
+	ErrInvalidMaxMemory    = "max memory must be greater than zero: %d"
+	ErrBoundRequired       = "an eviction policy requires max rows or memory"
+	ErrBoundedStore        = "bounded tables can't use a custom store"
+	ErrEvictingStore       = "an evict handler requires a bounded table"
+	ErrEvictHandlerType    = "evict handler type does not match table: %T"
+	ErrOrderedBounds       = "ordered tables can't be bounded"
+)
+
+// MaxRows bounds the number of rows a Table holds in memory. Once the bound
+// is reached, writing a new row evicts an existing one according to the
+// Table's Eviction policy. A sharded Table divides the bound between its
+// shards, so each shard evicts on its own
+func MaxRows(n int) Option {
+	return func(c *Config) error {
+		if n <= 0 {
+			return fmt.Errorf(ErrInvalidMaxRows, n)
+		}
+		if c.MaxRows != 0 {
+			return errors.New(ErrMaxRowsAlreadySet)
+		}
+		c.MaxRows = n
+		return nil
+	}
+}
+
+// MaxMemory bounds the approximate number of bytes a Table's rows take up in
+// memory, as estimated from their Keys and Values. Once the bound is reached,
+// rows are evicted according to the Table's Eviction policy. A sharded Table
+// divides the bound between its shards
+func MaxMemory(bytes int64) Option {
+	return func(c *Config) error {
+		if bytes <= 0 {
+			return fmt.Errorf(ErrInvalidMaxMemory, bytes)
+		}
+		if c.MaxMemory != 0 {
+			return errors.New(ErrMaxMemoryAlreadySet)
+		}
+		c.MaxMemory = bytes
+		return nil
+	}
+}
+
+// Eviction configures the Policy a bounded Table uses to choose the rows it
+// evicts. By default, the least recently used rows are evicted
+func Eviction(p store.Policy) Option {
+	return func(c *Config) error {
+		if c.Eviction != 0 {
+			return errors.New(ErrEvictionAlreadySet)
+		}
+		c.Eviction = p
+		return nil
+	}
+}
+
+// OnEvict registers a handler that is called for every row a Table evicts.
+// The handler is invoked outside the Table's lock, so it is free to interact
+// with the Table
+func OnEvict[Key comparable, Value any](h EvictHandler[Key, Value]) Option {
+	return func(c *Config) error {
+		if c.OnEvict != nil {
+			return errors.New(ErrOnEvictAlreadySet)
+		}
+		c.OnEvict = h
+		return nil
+	}
+}
@@ -0,0 +1,53 @@
+package config_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/caravan/streaming"
+	"github.com/caravan/streaming/table/config"
+	"github.com/caravan/streaming/table/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvictionConflicts(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := streaming.NewTableWith[string, any](columns,
+		config.MaxRows(10), config.MaxRows(20),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, config.ErrMaxRowsAlreadySet)
+
+	tbl, err = streaming.NewTableWith[string, any](columns,
+		config.MaxMemory(10), config.MaxMemory(20),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, config.ErrMaxMemoryAlreadySet)
+
+	tbl, err = streaming.NewTableWith[string, any](columns,
+		config.MaxRows(10),
+		config.Eviction(store.LeastRecentlyUsed),
+		config.Eviction(store.LeastFrequentlyUsed),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, config.ErrEvictionAlreadySet)
+
+	handler := func(string, []any) {}
+	tbl, err = streaming.NewTableWith[string, any](columns,
+		config.MaxRows(10),
+		config.OnEvict(handler), config.OnEvict(handler),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, config.ErrOnEvictAlreadySet)
+
+	tbl, err = streaming.NewTableWith[string, any](columns, config.MaxRows(0))
+	as.Nil(tbl)
+	as.EqualError(err, fmt.Sprintf(config.ErrInvalidMaxRows, 0))
+
+	tbl, err = streaming.NewTableWith[string, any](columns,
+		config.MaxMemory(-1),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, fmt.Sprintf(config.ErrInvalidMaxMemory, -1))
+}
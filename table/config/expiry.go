@@ -0,0 +1,72 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ExpireHandler is called with the Key and full set of column Values for each
+// row that a Table expires
+type ExpireHandler[Key comparable, Value any] func(Key, []Value)
+
+// Error messages
+const (
+	ErrTTLAlreadySet           = "ttl already set in table"
+	ErrSweepIntervalAlreadySet = "sweep interval already set in table"
+	ErrOnExpireAlreadySet      = "expire handler already set in table"
+	ErrInvalidDuration         = "duration must be greater than zero: %s"
+	ErrTTLRequired             = "an expire handler requires a table ttl"
+	ErrExpireHandlerType       = "expire handler type does not match table: %T"
+)
+
+// TTL configures a Table to expire each of its rows once the specified
+// Duration has passed since the row was last written
+func TTL(d time.Duration) Option {
+	return func(c *Config) error {
+		if err := checkDuration(d); err != nil {
+			return err
+		}
+		if c.TTL != 0 {
+			return errors.New(ErrTTLAlreadySet)
+		}
+		c.TTL = d
+		return nil
+	}
+}
+
+// SweepInterval configures how often a Table with a TTL checks for expired
+// rows in the background. Expired rows are never returned by a Getter, even
+// if they haven't been swept yet
+func SweepInterval(d time.Duration) Option {
+	return func(c *Config) error {
+		if err := checkDuration(d); err != nil {
+			return err
+		}
+		if c.SweepInterval != 0 {
+			return errors.New(ErrSweepIntervalAlreadySet)
+		}
+		c.SweepInterval = d
+		return nil
+	}
+}
+
+// OnExpire registers a handler that is called for every row a Table expires.
+// The handler is invoked outside the Table's lock, so it is free to interact
+// with the Table
+func OnExpire[Key comparable, Value any](h ExpireHandler[Key, Value]) Option {
+	return func(c *Config) error {
+		if c.OnExpire != nil {
+			return errors.New(ErrOnExpireAlreadySet)
+		}
+		c.OnExpire = h
+		return nil
+	}
+}
+
+func checkDuration(d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf(ErrInvalidDuration, d)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package config_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/caravan/streaming"
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/config"
+	"github.com/stretchr/testify/assert"
+)
+
+var columns = []table.ColumnName{"name"}
+
+func TestExpiryConflicts(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := streaming.NewTableWith[string, any](columns,
+		config.TTL(time.Second), config.TTL(time.Minute),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, config.ErrTTLAlreadySet)
+
+	tbl, err = streaming.NewTableWith[string, any](columns,
+		config.SweepInterval(time.Second), config.SweepInterval(time.Minute),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, config.ErrSweepIntervalAlreadySet)
+
+	tbl, err = streaming.NewTableWith[string, any](columns,
+		config.TTL(time.Second),
+		config.OnExpire(func(string, []any) {}),
+		config.OnExpire(func(string, []any) {}),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, config.ErrOnExpireAlreadySet)
+}
+
+func TestInvalidDurations(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := streaming.NewTableWith[string, any](columns, config.TTL(0))
+	as.Nil(tbl)
+	as.EqualError(err, fmt.Sprintf(config.ErrInvalidDuration, time.Duration(0)))
+
+	tbl, err = streaming.NewTableWith[string, any](columns,
+		config.SweepInterval(-time.Second),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, fmt.Sprintf(config.ErrInvalidDuration, -time.Second))
+}
+
+func TestDefaults(t *testing.T) {
+	as := assert.New(t)
+
+	cfg := config.ApplyDefaults(&config.Config{})
+	as.Equal(config.DefaultSweepInterval, cfg.SweepInterval)
+
+	cfg = config.ApplyDefaults(&config.Config{TTL: time.Millisecond})
+	as.Equal(time.Millisecond, cfg.SweepInterval)
+
+	cfg = &config.Config{}
+	as.Nil(config.ApplyOptions(cfg, config.TTL(time.Minute), config.Defaults))
+	as.Equal(time.Minute, cfg.TTL)
+	as.Equal(config.DefaultSweepInterval, cfg.SweepInterval)
+}
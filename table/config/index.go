@@ -0,0 +1,45 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/caravan/streaming/table"
+)
+
+// Error messages
+const (
+	ErrIndexColumnsRequired = "an index requires at least one column"
+	ErrIndexAlreadySet      = "index already set in table: %v"
+)
+
+// Index declares a secondary index on the specified columns of a Table. The
+// index is maintained automatically as rows are written and deleted, and is
+// queried using a Finder. The Values stored in indexed columns must be
+// comparable
+func Index(cols ...table.ColumnName) Option {
+	return func(c *Config) error {
+		if len(cols) == 0 {
+			return errors.New(ErrIndexColumnsRequired)
+		}
+		for _, idx := range c.Indexes {
+			if sameColumns(idx, cols) {
+				return fmt.Errorf(ErrIndexAlreadySet, cols)
+			}
+		}
+		c.Indexes = append(c.Indexes, cols)
+		return nil
+	}
+}
+
+func sameColumns(l, r []table.ColumnName) bool {
+	if len(l) != len(r) {
+		return false
+	}
+	for i, n := range l {
+		if r[i] != n {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,24 @@
+package config_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/caravan/streaming"
+	"github.com/caravan/streaming/table/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexConflict(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := streaming.NewTableWith[string, any](columns,
+		config.Index("name"), config.Index("name"),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, fmt.Sprintf(config.ErrIndexAlreadySet, columns))
+
+	tbl, err = streaming.NewTableWith[string, any](columns, config.Index())
+	as.Nil(tbl)
+	as.EqualError(err, config.ErrIndexColumnsRequired)
+}
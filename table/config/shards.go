@@ -0,0 +1,31 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error messages
+const (
+	ErrShardsAlreadySet   = "shard count already set in table"
+	ErrInvalidShardCount  = "shard count must be greater than zero: %d"
+	ErrShardedPersistence = "sharded tables don't support snapshot files or changelogs"
+	ErrShardedOrdered     = "ordered tables can't be sharded"
+)
+
+// Shards configures a Table to split its rows across the specified number of
+// shards, each with its own lock, so that writes to different Keys rarely
+// wait on each other. Reads that span the whole Table, such as scans, lock
+// every shard. Sharded tables don't support snapshot files or changelogs
+func Shards(n int) Option {
+	return func(c *Config) error {
+		if n < 1 {
+			return fmt.Errorf(ErrInvalidShardCount, n)
+		}
+		if c.Shards != 0 {
+			return errors.New(ErrShardsAlreadySet)
+		}
+		c.Shards = n
+		return nil
+	}
+}
@@ -0,0 +1,30 @@
+package config_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/caravan/streaming"
+	"github.com/caravan/streaming/table/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardsConflict(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := streaming.NewTableWith[string, any](columns,
+		config.Shards(4), config.Shards(8),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, config.ErrShardsAlreadySet)
+
+	tbl, err = streaming.NewTableWith[string, any](columns, config.Shards(0))
+	as.Nil(tbl)
+	as.EqualError(err, fmt.Sprintf(config.ErrInvalidShardCount, 0))
+
+	tbl, err = streaming.NewTableWith[string, any](columns,
+		config.Shards(4), config.SnapshotFile("snapshot"),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, config.ErrShardedPersistence)
+}
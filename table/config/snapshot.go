@@ -0,0 +1,43 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+// Error messages
+const (
+	ErrSnapshotFileAlreadySet     = "snapshot file already set in table"
+	ErrSnapshotIntervalAlreadySet = "snapshot interval already set in table"
+	ErrSnapshotFileRequired       = "a snapshot interval requires a snapshot file"
+)
+
+// SnapshotFile configures a Table to restore its rows from the specified file
+// when it's constructed. If the file doesn't exist, the Table starts empty
+func SnapshotFile(path string) Option {
+	return func(c *Config) error {
+		if c.SnapshotFile != "" {
+			return errors.New(ErrSnapshotFileAlreadySet)
+		}
+		c.SnapshotFile = path
+		return nil
+	}
+}
+
+// SnapshotInterval configures a Table to automatically write a snapshot to its
+// SnapshotFile once the specified Duration has passed since the first write
+// that hasn't yet been captured. A Table that isn't being written to is never
+// snapshotted. Closing a Table takes a final snapshot if there are writes that
+// haven't been captured
+func SnapshotInterval(d time.Duration) Option {
+	return func(c *Config) error {
+		if err := checkDuration(d); err != nil {
+			return err
+		}
+		if c.SnapshotInterval != 0 {
+			return errors.New(ErrSnapshotIntervalAlreadySet)
+		}
+		c.SnapshotInterval = d
+		return nil
+	}
+}
@@ -0,0 +1,27 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/caravan/streaming"
+	"github.com/caravan/streaming/table/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotConflicts(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := streaming.NewTableWith[string, any](columns,
+		config.SnapshotFile("first"), config.SnapshotFile("second"),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, config.ErrSnapshotFileAlreadySet)
+
+	tbl, err = streaming.NewTableWith[string, any](columns,
+		config.SnapshotInterval(time.Second),
+		config.SnapshotInterval(time.Minute),
+	)
+	as.Nil(tbl)
+	as.EqualError(err, config.ErrSnapshotIntervalAlreadySet)
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/caravan/streaming/table/store"
+)
+
+// Error messages
+const (
+	ErrStoreAlreadySet = "store already set in table"
+	ErrStoreType       = "store factory is of the wrong type: %T"
+	ErrOrderedStore    = "ordered tables can't use a custom store"
+)
+
+// Store configures the Factory that creates the Store holding a Table's
+// rows. By default, rows are held in an unbounded in-memory map
+func Store[Key comparable, Value any](f store.Factory[Key, Value]) Option {
+	return func(c *Config) error {
+		if c.Store != nil {
+			return errors.New(ErrStoreAlreadySet)
+		}
+		c.Store = f
+		return nil
+	}
+}
@@ -0,0 +1,156 @@
+package format
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"github.com/caravan/streaming/table"
+)
+
+// ImportCSV sets the rows of a CSV file in the Table, returning how many rows
+// were imported. The file's first record names its fields. Fields are used
+// as their Values unless a Parser is provided for their column, so a Parser
+// is required for any column, or a Key, that doesn't hold strings. Empty
+// fields are skipped, leaving their columns unset, so that the nil Values
+// written by ExportCSV aren't read back as empty strings.
+//
+// Either every row is imported, or none of them are. To make this so, the
+// whole file is parsed and held in memory before any of it is imported, and
+// the Table is locked against other readers and writers while its rows are
+// being set
+func ImportCSV[Key comparable, Value any](
+	t table.Table[Key, Value], r io.Reader, l *Layout[Key, Value],
+) (int, error) {
+	m, err := makeMapping(t, l)
+	if err != nil {
+		return 0, err
+	}
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err == io.EOF {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	keyPos := -1
+	positions := map[string]int{}
+	for i, n := range header {
+		if n == m.key {
+			keyPos = i
+		}
+		positions[n] = i
+	}
+	if keyPos == -1 {
+		return 0, fmt.Errorf(ErrKeyFieldNotFound, m.key)
+	}
+	fields := m.fieldsOf(header)
+
+	var rows []*row[Key, Value]
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		line, _ := cr.FieldPos(0)
+		res, err := parseRecord(m, rec, keyPos, positions, fields)
+		if err != nil {
+			return 0, fmt.Errorf(ErrLine, line, err)
+		}
+		rows = append(rows, res)
+	}
+	if err := importRows(t, rows); err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
+
+func parseRecord[Key comparable, Value any](
+	m *mapping[Key, Value], rec []string, keyPos int,
+	positions map[string]int, fields []field,
+) (*row[Key, Value], error) {
+	k, err := m.parseKey(rec[keyPos], fromString[Key])
+	if err != nil {
+		return nil, err
+	}
+	res := &row[Key, Value]{
+		key:    k,
+		names:  make([]table.ColumnName, 0, len(fields)),
+		values: make([]Value, 0, len(fields)),
+	}
+	for _, f := range fields {
+		text := rec[positions[f.name]]
+		if text == "" {
+			continue
+		}
+		v, err := m.parse(f.column, text, fromString[Value])
+		if err != nil {
+			return nil, err
+		}
+		res.names = append(res.names, f.column)
+		res.values = append(res.values, v)
+	}
+	return res, nil
+}
+
+// ExportCSV writes a point-in-time snapshot of the Table's rows to a CSV
+// file, returning how many rows were exported. The file's first record names
+// its fields. Values are written using fmt.Sprint unless a Formatter is
+// provided for their column, and nil Values are written as empty fields
+func ExportCSV[Key comparable, Value any](
+	t table.Table[Key, Value], w io.Writer, l *Layout[Key, Value],
+) (int, error) {
+	m, err := makeMapping(t, l)
+	if err != nil {
+		return 0, err
+	}
+	fields := m.exported(t)
+	cw := csv.NewWriter(w)
+	header := make([]string, 0, len(fields)+1)
+	header = append(header, m.key)
+	for _, f := range fields {
+		header = append(header, f.name)
+	}
+	if err := cw.Write(header); err != nil {
+		return 0, err
+	}
+
+	var count int
+	var visitErr error
+	err = exportRows(t, fields, func(k Key, v []Value) bool {
+		rec := make([]string, len(v)+1)
+		if rec[0], visitErr = m.formatKey(k, toString[Key]); visitErr != nil {
+			return false
+		}
+		for i, f := range fields {
+			rec[i+1], visitErr = m.format(f.column, v[i], toString[Value])
+			if visitErr != nil {
+				return false
+			}
+		}
+		if visitErr = cw.Write(rec); visitErr != nil {
+			return false
+		}
+		count++
+		return true
+	})
+	if err == nil {
+		err = visitErr
+	}
+	if err != nil {
+		return count, err
+	}
+	cw.Flush()
+	return count, cw.Error()
+}
+
+func toString[T any](v T) (string, error) {
+	if e := any(v); e != nil {
+		return fmt.Sprint(e), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,236 @@
+// Package format imports rows into a Table from CSV and JSON Lines files, and
+// exports a Table's rows to them
+package format
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/caravan/streaming/table"
+)
+
+type (
+	// Layout describes how the fields of a file map to a Table's Key and
+	// columns. A nil Layout reads and writes the Key in a field named "key",
+	// and every column in a field of the same name
+	Layout[Key comparable, Value any] struct {
+		// Key names the field that holds each row's Key
+		Key string
+
+		// Columns maps field names to the ColumnNames they're stored in.
+		// If it's empty, every field that shares a name with a column is
+		// stored in that column, and other fields are ignored
+		Columns map[string]table.ColumnName
+
+		// ParseKey and FormatKey convert the Key to and from the text of
+		// its field
+		ParseKey  Parser[Key]
+		FormatKey Formatter[Key]
+
+		// Parsers and Formatters convert the Values of particular columns
+		// to and from the text of their fields
+		Parsers    map[table.ColumnName]Parser[Value]
+		Formatters map[table.ColumnName]Formatter[Value]
+	}
+
+	// Parser converts the text of a field into a Value. For CSV, the text
+	// is the field itself. For JSON Lines, it's the field's raw JSON
+	Parser[T any] func(string) (T, error)
+
+	// Formatter converts a Value into the text of a field. For CSV, the text
+	// is the field itself. For JSON Lines, it must be valid JSON
+	Formatter[T any] func(T) (string, error)
+
+	// mapping is a Layout that has been resolved against a Table's columns
+	mapping[Key comparable, Value any] struct {
+		*Layout[Key, Value]
+		key     string
+		columns map[string]table.ColumnName
+	}
+
+	// field is a file field that's stored in a column
+	field struct {
+		name   string
+		column table.ColumnName
+	}
+
+	// row is a parsed row waiting to be imported
+	row[Key comparable, Value any] struct {
+		key    Key
+		names  []table.ColumnName
+		values []Value
+	}
+)
+
+// DefaultKeyField is the name of the field that holds each row's Key if a
+// Layout doesn't name one
+const DefaultKeyField = "key"
+
+// Error messages
+const (
+	ErrKeyFieldNotFound = "key field not found: %s"
+	ErrParserRequired   = "a parser is required to produce a %T"
+	ErrFormatterInvalid = "formatter for %s produced invalid JSON: %s"
+	ErrLine             = "line %d: %v"
+)
+
+func makeMapping[Key comparable, Value any](
+	t table.Table[Key, Value], l *Layout[Key, Value],
+) (*mapping[Key, Value], error) {
+	if l == nil {
+		l = &Layout[Key, Value]{}
+	}
+	res := &mapping[Key, Value]{
+		Layout:  l,
+		key:     l.Key,
+		columns: l.Columns,
+	}
+	if res.key == "" {
+		res.key = DefaultKeyField
+	}
+	if len(res.columns) == 0 {
+		res.columns = map[string]table.ColumnName{}
+		for _, c := range t.Columns() {
+			res.columns[string(c)] = c
+		}
+		return res, nil
+	}
+	for _, c := range res.columns {
+		if !hasColumn(t, c) {
+			return nil, fmt.Errorf(table.ErrColumnNotFound, c)
+		}
+	}
+	return res, nil
+}
+
+// fieldsOf returns the fields of a file that are stored in columns, in the
+// order they appear
+func (m *mapping[_, _]) fieldsOf(names []string) []field {
+	var res []field
+	for _, n := range names {
+		if c, ok := m.columns[n]; ok && n != m.key {
+			res = append(res, field{name: n, column: c})
+		}
+	}
+	return res
+}
+
+// exported returns the fields that an export writes, in the order of the
+// Table's columns
+func (m *mapping[Key, Value]) exported(t table.Table[Key, Value]) []field {
+	var res []field
+	for _, c := range t.Columns() {
+		for n, mc := range m.columns {
+			if mc == c && n != m.key {
+				res = append(res, field{name: n, column: c})
+			}
+		}
+	}
+	return res
+}
+
+func (m *mapping[Key, _]) parseKey(
+	s string, fallback Parser[Key],
+) (Key, error) {
+	if m.ParseKey != nil {
+		return m.ParseKey(s)
+	}
+	return fallback(s)
+}
+
+func (m *mapping[_, Value]) parse(
+	c table.ColumnName, s string, fallback Parser[Value],
+) (Value, error) {
+	if p, ok := m.Parsers[c]; ok {
+		return p(s)
+	}
+	return fallback(s)
+}
+
+func (m *mapping[Key, _]) formatKey(
+	k Key, fallback Formatter[Key],
+) (string, error) {
+	if m.FormatKey != nil {
+		return m.FormatKey(k)
+	}
+	return fallback(k)
+}
+
+func (m *mapping[_, Value]) format(
+	c table.ColumnName, v Value, fallback Formatter[Value],
+) (string, error) {
+	if f, ok := m.Formatters[c]; ok {
+		return f(v)
+	}
+	return fallback(v)
+}
+
+// importRows writes the parsed rows to the Table in a single Transaction, so
+// that a file is either imported completely or not at all. A row only sets
+// the columns that its fields provide
+func importRows[Key comparable, Value any](
+	t table.Table[Key, Value], rows []*row[Key, Value],
+) error {
+	tx := t.Begin()
+	setters := map[string]table.Setter[Key, Value]{}
+	for _, r := range rows {
+		id := columnsID(r.names)
+		set, ok := setters[id]
+		if !ok {
+			var err error
+			if set, err = tx.Setter(r.names...); err != nil {
+				tx.Rollback()
+				return err
+			}
+			setters[id] = set
+		}
+		if err := set(r.key, r.values...); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// exportRows visits a point-in-time snapshot of the Table's exported fields
+func exportRows[Key comparable, Value any](
+	t table.Table[Key, Value], fields []field, fn table.Visitor[Key, Value],
+) error {
+	names := make([]table.ColumnName, len(fields))
+	for i, f := range fields {
+		names[i] = f.column
+	}
+	scan, err := t.Scanner(names...)
+	if err != nil {
+		return err
+	}
+	return scan(fn)
+}
+
+func fromString[T any](s string) (T, error) {
+	if res, ok := any(s).(T); ok {
+		return res, nil
+	}
+	var zero T
+	return zero, fmt.Errorf(ErrParserRequired, zero)
+}
+
+func columnsID(names []table.ColumnName) string {
+	var b strings.Builder
+	for _, n := range names {
+		b.WriteString(string(n))
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+func hasColumn[Key comparable, Value any](
+	t table.Table[Key, Value], c table.ColumnName,
+) bool {
+	for _, n := range t.Columns() {
+		if n == c {
+			return true
+		}
+	}
+	return false
+}
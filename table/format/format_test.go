@@ -0,0 +1,180 @@
+package format_test
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/caravan/streaming"
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/format"
+	"github.com/stretchr/testify/assert"
+)
+
+// sortedLines returns the lines of an export, sorting those after the first
+// skipped lines because the order in which rows are exported is unspecified
+func sortedLines(s string, skip int) []string {
+	res := strings.Split(strings.TrimSpace(s), "\n")
+	sort.Strings(res[skip:])
+	return res
+}
+
+func TestCSV(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := streaming.NewTable[string, any]("name", "age")
+	as.NotNil(tbl)
+	as.Nil(err)
+
+	in := "id,full name,age,ignored\n" +
+		"1,Bill,42,x\n" +
+		"2,\"Smith, Jane\",37,y\n"
+	n, err := format.ImportCSV(tbl, strings.NewReader(in),
+		&format.Layout[string, any]{
+			Key: "id",
+			Columns: map[string]table.ColumnName{
+				"full name": "name",
+				"age":       "age",
+			},
+			Parsers: map[table.ColumnName]format.Parser[any]{
+				"age": func(s string) (any, error) {
+					return strconv.Atoi(s)
+				},
+			},
+		},
+	)
+	as.Nil(err)
+	as.Equal(2, n)
+
+	get, err := tbl.Getter("name", "age")
+	as.Nil(err)
+	res, err := get("2")
+	as.Nil(err)
+	as.Equal([]any{"Smith, Jane", 37}, res)
+
+	var out bytes.Buffer
+	n, err = format.ExportCSV(tbl, &out, nil)
+	as.Nil(err)
+	as.Equal(2, n)
+	as.Equal([]string{
+		"key,name,age",
+		"1,Bill,42",
+		"2,\"Smith, Jane\",37",
+	}, sortedLines(out.String(), 1))
+}
+
+func TestCSVNil(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := streaming.NewTable[string, any]("name", "nickname")
+	as.NotNil(tbl)
+	as.Nil(err)
+	set, err := tbl.Setter("name")
+	as.Nil(err)
+	as.Nil(set("1", "Bill"))
+
+	var out bytes.Buffer
+	n, err := format.ExportCSV(tbl, &out, nil)
+	as.Nil(err)
+	as.Equal(1, n)
+	as.Equal("key,name,nickname\n1,Bill,\n", out.String())
+
+	// The empty field is skipped, so the nil Value survives the round trip
+	copied, err := streaming.NewTable[string, any]("name", "nickname")
+	as.NotNil(copied)
+	as.Nil(err)
+	n, err = format.ImportCSV(copied, &out, nil)
+	as.Nil(err)
+	as.Equal(1, n)
+
+	get, err := copied.PresenceGetter("name", "nickname")
+	as.Nil(err)
+	res, present, err := get("1")
+	as.Nil(err)
+	as.Equal([]any{"Bill", nil}, res)
+	as.Equal([]bool{true, false}, present)
+}
+
+func TestCSVErrors(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := streaming.NewTable[int, any]("name")
+	as.NotNil(tbl)
+	as.Nil(err)
+
+	_, err = format.ImportCSV(tbl, strings.NewReader("id,name\n1,Bill\n"), nil)
+	as.EqualError(err, fmt.Sprintf(format.ErrKeyFieldNotFound, "key"))
+
+	_, err = format.ImportCSV(tbl, strings.NewReader("key,name\n1,Bill\n"), nil)
+	as.EqualError(err, fmt.Sprintf(format.ErrLine, 2,
+		fmt.Errorf(format.ErrParserRequired, 0),
+	))
+
+	_, err = format.ImportCSV(tbl, strings.NewReader("key,name\n"),
+		&format.Layout[int, any]{
+			Columns: map[string]table.ColumnName{"name": "missing"},
+		},
+	)
+	as.EqualError(err, fmt.Sprintf(table.ErrColumnNotFound, "missing"))
+
+	// A row that fails to parse leaves the Table untouched
+	_, err = format.ImportCSV(tbl,
+		strings.NewReader("key,name\n1,Bill\n2,Jane\nx,Bob\n"),
+		&format.Layout[int, any]{ParseKey: strconv.Atoi},
+	)
+	as.Error(err)
+	as.Equal(0, tbl.Len())
+}
+
+func TestJSONLines(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := streaming.NewTable[int, any]("name", "age")
+	as.NotNil(tbl)
+	as.Nil(err)
+
+	in := `{"id":1,"name":"Bill","age":42}` + "\n\n" +
+		`{"id":2,"name":"Jane"}` + "\n"
+	n, err := format.ImportJSONLines(tbl, strings.NewReader(in),
+		&format.Layout[int, any]{Key: "id"},
+	)
+	as.Nil(err)
+	as.Equal(2, n)
+
+	get, err := tbl.Getter("name", "age")
+	as.Nil(err)
+	res, err := get(1)
+	as.Nil(err)
+	as.Equal([]any{"Bill", 42.0}, res)
+	res, err = get(2)
+	as.Nil(err)
+	as.Equal([]any{"Jane", nil}, res)
+
+	var out bytes.Buffer
+	n, err = format.ExportJSONLines(tbl, &out, &format.Layout[int, any]{
+		Formatters: map[table.ColumnName]format.Formatter[any]{
+			"name": func(v any) (string, error) {
+				return strconv.Quote(strings.ToUpper(v.(string))), nil
+			},
+		},
+	})
+	as.Nil(err)
+	as.Equal(2, n)
+	as.Equal([]string{
+		`{"key":1,"name":"BILL","age":42}`,
+		`{"key":2,"name":"JANE","age":null}`,
+	}, sortedLines(out.String(), 0))
+
+	_, err = format.ImportJSONLines(tbl, strings.NewReader(`{"name":1}`), nil)
+	as.EqualError(err, fmt.Sprintf(format.ErrLine, 1,
+		fmt.Errorf(format.ErrKeyFieldNotFound, "key"),
+	))
+
+	_, err = format.ExportJSONLines(tbl, &out, &format.Layout[int, any]{
+		FormatKey: func(int) (string, error) { return "{", nil },
+	})
+	as.EqualError(err, fmt.Sprintf(format.ErrFormatterInvalid, "key", "{"))
+}
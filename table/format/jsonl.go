@@ -0,0 +1,173 @@
+package format
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/caravan/streaming/table"
+)
+
+// ImportJSONLines sets the rows of a JSON Lines file in the Table, returning
+// how many rows were imported. Each line is an object whose fields are
+// decoded into their Values using encoding/json, unless a Parser is provided
+// for their column. A row only sets the columns for which its object has
+// fields, and blank lines are skipped.
+//
+// Either every row is imported, or none of them are. To make this so, the
+// whole file is parsed and held in memory before any of it is imported, and
+// the Table is locked against other readers and writers while its rows are
+// being set
+func ImportJSONLines[Key comparable, Value any](
+	t table.Table[Key, Value], r io.Reader, l *Layout[Key, Value],
+) (int, error) {
+	m, err := makeMapping(t, l)
+	if err != nil {
+		return 0, err
+	}
+	var rows []*row[Key, Value]
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, bufio.MaxScanTokenSize*16)
+	for line := 1; s.Scan(); line++ {
+		text := bytes.TrimSpace(s.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		res, err := parseObject(m, text)
+		if err != nil {
+			return 0, fmt.Errorf(ErrLine, line, err)
+		}
+		rows = append(rows, res)
+	}
+	if err := s.Err(); err != nil {
+		return 0, err
+	}
+	if err := importRows(t, rows); err != nil {
+		return 0, err
+	}
+	return len(rows), nil
+}
+
+func parseObject[Key comparable, Value any](
+	m *mapping[Key, Value], text []byte,
+) (*row[Key, Value], error) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(text, &obj); err != nil {
+		return nil, err
+	}
+	raw, ok := obj[m.key]
+	if !ok {
+		return nil, fmt.Errorf(ErrKeyFieldNotFound, m.key)
+	}
+	k, err := m.parseKey(string(raw), fromJSON[Key])
+	if err != nil {
+		return nil, err
+	}
+
+	// Fields are visited in name order, so that rows with the same fields
+	// share a Setter
+	names := make([]string, 0, len(obj))
+	for n := range obj {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	fields := m.fieldsOf(names)
+	res := &row[Key, Value]{
+		key:    k,
+		names:  make([]table.ColumnName, len(fields)),
+		values: make([]Value, len(fields)),
+	}
+	for i, f := range fields {
+		v, err := m.parse(f.column, string(obj[f.name]), fromJSON[Value])
+		if err != nil {
+			return nil, err
+		}
+		res.names[i] = f.column
+		res.values[i] = v
+	}
+	return res, nil
+}
+
+// ExportJSONLines writes a point-in-time snapshot of the Table's rows to a
+// JSON Lines file, returning how many rows were exported. Each row is written
+// as an object whose fields are encoded using encoding/json, unless a
+// Formatter is provided for their column
+func ExportJSONLines[Key comparable, Value any](
+	t table.Table[Key, Value], w io.Writer, l *Layout[Key, Value],
+) (int, error) {
+	m, err := makeMapping(t, l)
+	if err != nil {
+		return 0, err
+	}
+	fields := m.exported(t)
+	names := make([][]byte, len(fields))
+	for i, f := range fields {
+		if names[i], err = json.Marshal(f.name); err != nil {
+			return 0, err
+		}
+	}
+	keyName, err := json.Marshal(m.key)
+	if err != nil {
+		return 0, err
+	}
+
+	bw := bufio.NewWriter(w)
+	var count int
+	var visitErr error
+	err = exportRows(t, fields, func(k Key, v []Value) bool {
+		var buf bytes.Buffer
+		buf.WriteByte('{')
+		buf.Write(keyName)
+		buf.WriteByte(':')
+		var text string
+		if text, visitErr = m.formatKey(k, toJSON[Key]); visitErr != nil {
+			return false
+		}
+		if !json.Valid([]byte(text)) {
+			visitErr = fmt.Errorf(ErrFormatterInvalid, m.key, text)
+			return false
+		}
+		buf.WriteString(text)
+		for i, f := range fields {
+			text, visitErr = m.format(f.column, v[i], toJSON[Value])
+			if visitErr != nil {
+				return false
+			}
+			if !json.Valid([]byte(text)) {
+				visitErr = fmt.Errorf(ErrFormatterInvalid, f.column, text)
+				return false
+			}
+			buf.WriteByte(',')
+			buf.Write(names[i])
+			buf.WriteByte(':')
+			buf.WriteString(text)
+		}
+		buf.WriteString("}\n")
+		if _, visitErr = bw.Write(buf.Bytes()); visitErr != nil {
+			return false
+		}
+		count++
+		return true
+	})
+	if err == nil {
+		err = visitErr
+	}
+	if err != nil {
+		return count, err
+	}
+	return count, bw.Flush()
+}
+
+func fromJSON[T any](s string) (T, error) {
+	var res T
+	err := json.Unmarshal([]byte(s), &res)
+	return res, err
+}
+
+func toJSON[T any](v T) (string, error) {
+	res, err := json.Marshal(v)
+	return string(res), err
+}
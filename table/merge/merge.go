@@ -0,0 +1,164 @@
+// Package merge provides Reducers for combining a column's existing Value with
+// a newly written one. They work with any Value type, including any, by
+// inspecting the dynamic types of the Values they're given
+package merge
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/caravan/streaming/table"
+)
+
+// Error messages
+const (
+	ErrIncompatibleValues = "values can't be merged: %T and %T"
+	ErrNotNumeric         = "values must be numeric to be summed: %T"
+	ErrNotOrdered         = "values must be numeric or strings to be compared: %T"
+	ErrNotSlice           = "values must be slices to be appended: %T"
+)
+
+// Sum adds the new Value to the existing one. Both must be numbers of the
+// same type
+func Sum[Value any]() table.Reducer[Value] {
+	return func(old, v Value) (Value, error) {
+		o, n, err := sameType(old, v)
+		if err != nil {
+			return old, err
+		}
+		var res reflect.Value
+		switch o.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+			reflect.Int64:
+			res = reflect.ValueOf(o.Int() + n.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+			reflect.Uint64, reflect.Uintptr:
+			res = reflect.ValueOf(o.Uint() + n.Uint())
+		case reflect.Float32, reflect.Float64:
+			res = reflect.ValueOf(o.Float() + n.Float())
+		case reflect.Complex64, reflect.Complex128:
+			res = reflect.ValueOf(o.Complex() + n.Complex())
+		default:
+			return old, fmt.Errorf(ErrNotNumeric, old)
+		}
+		return res.Convert(o.Type()).Interface().(Value), nil
+	}
+}
+
+// Max keeps the greater of the existing and new Values. Both must be numbers
+// or strings of the same type
+func Max[Value any]() table.Reducer[Value] {
+	return func(old, v Value) (Value, error) {
+		c, err := compare(old, v)
+		if err != nil || c >= 0 {
+			return old, err
+		}
+		return v, nil
+	}
+}
+
+// Min keeps the lesser of the existing and new Values. Both must be numbers
+// or strings of the same type
+func Min[Value any]() table.Reducer[Value] {
+	return func(old, v Value) (Value, error) {
+		c, err := compare(old, v)
+		if err != nil || c <= 0 {
+			return old, err
+		}
+		return v, nil
+	}
+}
+
+// Append appends the elements of the new Value to the existing one. Both must
+// be slices of the same type
+func Append[Value any]() table.Reducer[Value] {
+	return func(old, v Value) (Value, error) {
+		o, n, err := sameType(old, v)
+		if err != nil {
+			return old, err
+		}
+		if o.Kind() != reflect.Slice {
+			return old, fmt.Errorf(ErrNotSlice, old)
+		}
+		// The existing slice may be shared by a Change or a Snapshot, so its
+		// backing array is never written to
+		res := reflect.MakeSlice(o.Type(), 0, o.Len()+n.Len())
+		res = reflect.AppendSlice(res, o)
+		res = reflect.AppendSlice(res, n)
+		return res.Interface().(Value), nil
+	}
+}
+
+// LastNonNull replaces the existing Value with the new one, unless the new one
+// is nil, in which case the existing Value is kept
+func LastNonNull[Value any]() table.Reducer[Value] {
+	return func(old, v Value) (Value, error) {
+		if isNil(reflect.ValueOf(&v).Elem()) {
+			return old, nil
+		}
+		return v, nil
+	}
+}
+
+// compare returns -1, 0, or 1 depending on whether l is less than, equal to,
+// or greater than r
+func compare[Value any](l, r Value) (int, error) {
+	lv, rv, err := sameType(l, r)
+	if err != nil {
+		return 0, err
+	}
+	switch lv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return order(lv.Int() < rv.Int(), lv.Int() > rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return order(lv.Uint() < rv.Uint(), lv.Uint() > rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return order(lv.Float() < rv.Float(), lv.Float() > rv.Float()), nil
+	case reflect.String:
+		return order(lv.String() < rv.String(), lv.String() > rv.String()), nil
+	default:
+		return 0, fmt.Errorf(ErrNotOrdered, l)
+	}
+}
+
+func order(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// sameType returns the dynamic Values of l and r, making sure that they're of
+// the same type
+func sameType[Value any](l, r Value) (reflect.Value, reflect.Value, error) {
+	lv := dynamic(reflect.ValueOf(&l).Elem())
+	rv := dynamic(reflect.ValueOf(&r).Elem())
+	if !lv.IsValid() || !rv.IsValid() || lv.Type() != rv.Type() {
+		return lv, rv, fmt.Errorf(ErrIncompatibleValues, l, r)
+	}
+	return lv, rv, nil
+}
+
+// dynamic unwraps an interface to the Value it holds
+func dynamic(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Interface {
+		return v.Elem()
+	}
+	return v
+}
+
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer, reflect.Slice, reflect.Map,
+		reflect.Chan, reflect.Func:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
@@ -0,0 +1,96 @@
+package merge_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/caravan/streaming/table/merge"
+	"github.com/stretchr/testify/assert"
+)
+
+type cents int
+
+func TestSum(t *testing.T) {
+	as := assert.New(t)
+
+	res, err := merge.Sum[int]()(40, 2)
+	as.Nil(err)
+	as.Equal(42, res)
+
+	sum := merge.Sum[any]()
+	for _, c := range []struct{ old, new, res any }{
+		{int64(1), int64(2), int64(3)},
+		{uint8(1), uint8(2), uint8(3)},
+		{1.5, 2.0, 3.5},
+		{cents(10), cents(5), cents(15)},
+	} {
+		res, err := sum(c.old, c.new)
+		as.Nil(err)
+		as.Equal(c.res, res)
+	}
+
+	_, err = sum(1, "2")
+	as.EqualError(err, fmt.Sprintf(merge.ErrIncompatibleValues, 1, "2"))
+	_, err = sum(nil, 2)
+	as.EqualError(err, fmt.Sprintf(merge.ErrIncompatibleValues, nil, 2))
+	_, err = sum("1", "2")
+	as.EqualError(err, fmt.Sprintf(merge.ErrNotNumeric, "1"))
+}
+
+func TestMaxMin(t *testing.T) {
+	as := assert.New(t)
+
+	max := merge.Max[any]()
+	min := merge.Min[any]()
+
+	res, _ := max(1, 2)
+	as.Equal(2, res)
+	res, _ = max(2, 1)
+	as.Equal(2, res)
+	res, _ = min(1, 2)
+	as.Equal(1, res)
+	res, _ = min(uint(2), uint(1))
+	as.Equal(uint(1), res)
+	res, _ = max("apple", "banana")
+	as.Equal("banana", res)
+	res, _ = min(2.5, 1.5)
+	as.Equal(1.5, res)
+
+	_, err := max(true, false)
+	as.EqualError(err, fmt.Sprintf(merge.ErrNotOrdered, true))
+	_, err = min(1, 1.5)
+	as.EqualError(err, fmt.Sprintf(merge.ErrIncompatibleValues, 1, 1.5))
+}
+
+func TestAppend(t *testing.T) {
+	as := assert.New(t)
+
+	old := make([]string, 1, 10)
+	old[0] = "a"
+	res, err := merge.Append[[]string]()(old, []string{"b", "c"})
+	as.Nil(err)
+	as.Equal([]string{"a", "b", "c"}, res)
+
+	// The existing slice's backing array isn't written to
+	as.Equal([]string{"a", "", ""}, old[:3])
+
+	_, err = merge.Append[any]()("a", "b")
+	as.EqualError(err, fmt.Sprintf(merge.ErrNotSlice, "a"))
+}
+
+func TestLastNonNull(t *testing.T) {
+	as := assert.New(t)
+
+	last := merge.LastNonNull[any]()
+	res, _ := last("a", "b")
+	as.Equal("b", res)
+	res, _ = last("a", nil)
+	as.Equal("a", res)
+	res, _ = last("a", 0)
+	as.Equal(0, res)
+
+	var none *int
+	one := 1
+	ptr, _ := merge.LastNonNull[*int]()(&one, none)
+	as.Equal(&one, ptr)
+}
@@ -0,0 +1,69 @@
+package table
+
+type (
+	// OrderedTable is a Table whose rows are kept in Key order. Its Scanners
+	// visit rows in Key order, and it can create Rangers for performing
+	// ordered queries
+	OrderedTable[Key Ordered, Value any] interface {
+		Table[Key, Value]
+
+		// Ranger creates a Ranger based on the specified ColumnNames
+		Ranger(...ColumnName) (Ranger[Key, Value], error)
+	}
+
+	// Ranger performs ordered queries against an OrderedTable, retrieving a
+	// pre-defined set of column Values. Queries report an error if one of
+	// those columns has been dropped
+	Ranger[Key Ordered, Value any] interface {
+		// Range returns a Scanner that visits, in Key order, the rows
+		// whose Keys fall within the half-open interval [from, to)
+		Range(from, to Key) Scanner[Key, Value]
+
+		// From returns a Scanner that visits, in Key order, the rows whose
+		// Keys are greater than or equal to the provided Key
+		From(Key) Scanner[Key, Value]
+
+		// Until returns a Scanner that visits, in Key order, the rows whose
+		// Keys are less than the provided Key
+		Until(Key) Scanner[Key, Value]
+
+		// Floor returns the row with the greatest Key that is less than or
+		// equal to the provided Key
+		Floor(Key) (Key, []Value, bool, error)
+
+		// Ceiling returns the row with the least Key that is greater than
+		// or equal to the provided Key
+		Ceiling(Key) (Key, []Value, bool, error)
+
+		// Min returns the row with the least Key
+		Min() (Key, []Value, bool, error)
+
+		// Max returns the row with the greatest Key
+		Max() (Key, []Value, bool, error)
+	}
+)
+
+// Prefix returns a Scanner that visits, in Key order, the rows whose Keys
+// start with the provided prefix
+func Prefix[Key ~string, Value any](
+	r Ranger[Key, Value], prefix Key,
+) Scanner[Key, Value] {
+	if to, ok := prefixEnd(string(prefix)); ok {
+		return r.Range(prefix, Key(to))
+	}
+	return r.From(prefix)
+}
+
+// prefixEnd returns the least string that is greater than every string that
+// starts with the prefix. There is no such string if the prefix consists only
+// of 0xff bytes
+func prefixEnd(prefix string) (string, bool) {
+	b := []byte(prefix)
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i] < 0xff {
+			b[i]++
+			return string(b[:i+1]), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,41 @@
+package table
+
+import "fmt"
+
+// PresenceGetter is a function that is capable of retrieving a pre-defined
+// set of column Values from a Table based on the provided Key, along with
+// whether each column has been set in the row. A column that was never set
+// holds its declared default, or the zero Value if it has none. Presence is
+// kept by snapshot files and changelog files, but not by changelog Topics or
+// the disk Store, so every column of a row restored from either of those is
+// considered set
+type PresenceGetter[Key comparable, Value any] func(Key) ([]Value, []bool, error)
+
+// Error messages
+const (
+	ErrColumnNotSet = "column not set for key %v: %s"
+)
+
+// RequiredGetter creates a Getter for the specified ColumnNames that only
+// succeeds if every one of them has been set in the row. Otherwise, it reports
+// the first column that hasn't been
+func RequiredGetter[Key comparable, Value any](
+	t Table[Key, Value], c ...ColumnName,
+) (Getter[Key, Value], error) {
+	get, err := t.PresenceGetter(c...)
+	if err != nil {
+		return nil, err
+	}
+	return func(k Key) ([]Value, error) {
+		res, set, err := get(k)
+		if err != nil {
+			return nil, err
+		}
+		for i, ok := range set {
+			if !ok {
+				return nil, fmt.Errorf(ErrColumnNotSet, k, c[i])
+			}
+		}
+		return res, nil
+	}, nil
+}
@@ -0,0 +1,473 @@
+// Package query exposes registered Tables to read-only queries over HTTP
+package query
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/caravan/streaming/table"
+)
+
+type (
+	// Handler is an http.Handler that answers read-only queries about the
+	// Tables registered with it. Every response is JSON. It serves the
+	// following paths, relative to where it's mounted:
+	//
+	//	GET /                       the registered Tables and their columns
+	//	GET /{table}/stats          a Table's size and usage
+	//	GET /{table}/keys           a page of a Table's Keys, in Key order
+	//	GET /{table}/rows/{key}     the row stored under a Key
+	//
+	// The keys path accepts offset and limit parameters, and the rows path
+	// accepts a columns parameter that holds a comma-separated list of the
+	// columns to return. Path segments are unescaped, so Keys may contain
+	// slashes if they're escaped. Rows fetched through a Handler count toward
+	// their Table's Lookups. Every request for a page of Keys copies and
+	// sorts all of the Table's Keys, so paging through a large Table costs
+	// time proportional to its size for each page
+	Handler struct {
+		sync.RWMutex
+		tables map[string]queryable
+	}
+
+	// KeyParser converts the text of a Key in a request path into a Key
+	KeyParser[Key comparable] func(string) (Key, error)
+
+	// Summary describes a registered Table
+	Summary struct {
+		Name    string             `json:"name"`
+		Columns []table.ColumnName `json:"columns"`
+	}
+
+	// Stats reports the size and usage of a registered Table. LockWait is
+	// reported in nanoseconds
+	Stats struct {
+		Name     string                           `json:"name"`
+		Columns  []table.ColumnName               `json:"columns"`
+		Rows     int                              `json:"rows"`
+		Bytes    int64                            `json:"bytes"`
+		Usage    map[table.ColumnName]ColumnStats `json:"usage"`
+		Lookups  Lookups                          `json:"lookups"`
+		MissRate float64                          `json:"miss_rate"`
+		LockWait time.Duration                    `json:"lock_wait"`
+	}
+
+	// ColumnStats counts the reads and writes of a registered Table's column
+	ColumnStats struct {
+		Reads  uint64 `json:"reads"`
+		Writes uint64 `json:"writes"`
+	}
+
+	// Lookups counts the Keys looked up in a registered Table
+	Lookups struct {
+		Hits   uint64 `json:"hits"`
+		Misses uint64 `json:"misses"`
+	}
+
+	// Keys is a page of a registered Table's Keys
+	Keys struct {
+		Keys   []any `json:"keys"`
+		Offset int   `json:"offset"`
+		Limit  int   `json:"limit"`
+		Total  int   `json:"total"`
+	}
+
+	// Row is the row stored under a Key of a registered Table, by column
+	Row struct {
+		Key    any                      `json:"key"`
+		Values map[table.ColumnName]any `json:"values"`
+	}
+
+	// Error is the body of every unsuccessful response
+	Error struct {
+		Error string `json:"error"`
+	}
+
+	// queryable is a registered Table with its type parameters erased
+	queryable interface {
+		summary() *Summary
+		stats() (*Stats, error)
+		keys() ([]any, error)
+		row(key string, c []table.ColumnName) (*Row, bool, error)
+	}
+
+	registered[Key comparable, Value any] struct {
+		name  string
+		table table.Table[Key, Value]
+		parse KeyParser[Key]
+	}
+
+	// statusError is an error that's reported with a specific HTTP status
+	statusError struct {
+		status int
+		error
+	}
+)
+
+const (
+	// DefaultLimit is how many Keys are returned if a request doesn't
+	// provide a limit
+	DefaultLimit = 100
+
+	// MaxLimit is the largest number of Keys that a request may ask for
+	MaxLimit = 10000
+)
+
+// Error messages
+const (
+	ErrNameRequired      = "table name is required"
+	ErrNameInvalid       = "table name can't contain a slash: %s"
+	ErrAlreadyRegistered = "table already registered: %s"
+	ErrTableNotFound     = "table not found: %s"
+	ErrPathNotFound      = "path not found: %s"
+	ErrMethodNotAllowed  = "method not allowed: %s"
+	ErrInvalidKey        = "invalid key %q: %v"
+	ErrInvalidParameter  = "invalid %s parameter: %s"
+)
+
+// NewHandler returns a Handler that has no Tables registered
+func NewHandler() *Handler {
+	return &Handler{
+		tables: map[string]queryable{},
+	}
+}
+
+// Register exposes a Table through the Handler under the provided name. Keys
+// in request paths are converted using the KeyParser. If it's nil, Keys are
+// used as they are if the Table's Key is a string, and are otherwise decoded
+// as JSON
+func Register[Key comparable, Value any](
+	h *Handler, name string, t table.Table[Key, Value], p KeyParser[Key],
+) error {
+	if name == "" {
+		return errors.New(ErrNameRequired)
+	}
+	if strings.Contains(name, "/") {
+		return fmt.Errorf(ErrNameInvalid, name)
+	}
+	if p == nil {
+		p = parseKey[Key]
+	}
+
+	h.Lock()
+	defer h.Unlock()
+	if _, ok := h.tables[name]; ok {
+		return fmt.Errorf(ErrAlreadyRegistered, name)
+	}
+	h.tables[name] = &registered[Key, Value]{
+		name:  name,
+		table: t,
+		parse: p,
+	}
+	return nil
+}
+
+// Unregister stops exposing the Table registered under the provided name
+func (h *Handler) Unregister(name string) {
+	h.Lock()
+	defer h.Unlock()
+	delete(h.tables, name)
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, &statusError{
+			status: http.StatusMethodNotAllowed,
+			error:  fmt.Errorf(ErrMethodNotAllowed, r.Method),
+		})
+		return
+	}
+	res, err := h.serve(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h *Handler) serve(r *http.Request) (any, error) {
+	path, err := splitPath(r.URL)
+	if err != nil {
+		return nil, err
+	}
+	if len(path) == 0 {
+		return h.summaries(), nil
+	}
+
+	q, err := h.lookup(path[0])
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case len(path) == 2 && path[1] == "stats":
+		return q.stats()
+	case len(path) == 2 && path[1] == "keys":
+		return pageKeys(q, r.URL.Query())
+	case len(path) == 3 && path[1] == "rows":
+		return getRow(q, path[2], r.URL.Query())
+	default:
+		return nil, notFound(fmt.Errorf(ErrPathNotFound, r.URL.Path))
+	}
+}
+
+func (h *Handler) summaries() []*Summary {
+	h.RLock()
+	defer h.RUnlock()
+	res := make([]*Summary, 0, len(h.tables))
+	for _, q := range h.tables {
+		res = append(res, q.summary())
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+func (h *Handler) lookup(name string) (queryable, error) {
+	h.RLock()
+	defer h.RUnlock()
+	if q, ok := h.tables[name]; ok {
+		return q, nil
+	}
+	return nil, notFound(fmt.Errorf(ErrTableNotFound, name))
+}
+
+func pageKeys(q queryable, params url.Values) (*Keys, error) {
+	offset, err := intParam(params, "offset", 0)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := intParam(params, "limit", DefaultLimit)
+	if err != nil {
+		return nil, err
+	}
+	if limit < 1 || limit > MaxLimit {
+		return nil, badRequest(
+			fmt.Errorf(ErrInvalidParameter, "limit", params.Get("limit")),
+		)
+	}
+	keys, err := q.keys()
+	if err != nil {
+		return nil, err
+	}
+	res := &Keys{
+		Keys:   []any{},
+		Offset: offset,
+		Limit:  limit,
+		Total:  len(keys),
+	}
+	if offset < len(keys) {
+		end := offset + limit
+		if end > len(keys) {
+			end = len(keys)
+		}
+		res.Keys = keys[offset:end]
+	}
+	return res, nil
+}
+
+func getRow(q queryable, key string, params url.Values) (*Row, error) {
+	var columns []table.ColumnName
+	if c := params.Get("columns"); c != "" {
+		for _, n := range strings.Split(c, ",") {
+			columns = append(columns, table.ColumnName(n))
+		}
+	}
+	res, ok, err := q.row(key, columns)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, notFound(fmt.Errorf(table.ErrKeyNotFound, key))
+	}
+	return res, nil
+}
+
+func (r *registered[_, _]) summary() *Summary {
+	return &Summary{
+		Name:    r.name,
+		Columns: r.table.Columns(),
+	}
+}
+
+func (r *registered[_, _]) stats() (*Stats, error) {
+	s, err := r.table.Stats()
+	if err != nil {
+		return nil, err
+	}
+	usage := make(map[table.ColumnName]ColumnStats, len(s.Columns))
+	for n, c := range s.Columns {
+		usage[n] = ColumnStats{
+			Reads:  c.Reads,
+			Writes: c.Writes,
+		}
+	}
+	return &Stats{
+		Name:    r.name,
+		Columns: r.table.Columns(),
+		Rows:    s.Rows,
+		Bytes:   s.Bytes,
+		Usage:   usage,
+		Lookups: Lookups{
+			Hits:   s.Lookups.Hits,
+			Misses: s.Lookups.Misses,
+		},
+		MissRate: s.Lookups.MissRate(),
+		LockWait: s.LockWait,
+	}, nil
+}
+
+// keys returns every Key of the Table in order. Nothing is cached, because a
+// Table doesn't report when its Keys change, so each call copies and sorts
+// all of them
+func (r *registered[Key, _]) keys() ([]any, error) {
+	keys, err := r.table.Keys()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]any, len(keys))
+	for i, k := range keys {
+		res[i] = k
+	}
+	sortKeys(res)
+	return res, nil
+}
+
+func (r *registered[Key, Value]) row(
+	key string, c []table.ColumnName,
+) (*Row, bool, error) {
+	k, err := r.parse(key)
+	if err != nil {
+		return nil, false, badRequest(fmt.Errorf(ErrInvalidKey, key, err))
+	}
+	if len(c) == 0 {
+		c = r.table.Columns()
+	}
+	get, err := r.table.MultiGetter(c...)
+	if err != nil {
+		return nil, false, badRequest(err)
+	}
+	found, _, err := get(k)
+	if err != nil {
+		return nil, false, err
+	}
+	values, ok := found[k]
+	if !ok {
+		return nil, false, nil
+	}
+	res := &Row{
+		Key:    k,
+		Values: make(map[table.ColumnName]any, len(c)),
+	}
+	for i, n := range c {
+		res.Values[n] = values[i]
+	}
+	return res, true, nil
+}
+
+func parseKey[Key comparable](s string) (Key, error) {
+	if res, ok := any(s).(Key); ok {
+		return res, nil
+	}
+	var res Key
+	err := json.Unmarshal([]byte(s), &res)
+	return res, err
+}
+
+// sortKeys orders Keys so that pages are stable. Numbers and strings of the
+// same kind are compared by value, and anything else by its formatted text
+func sortKeys(keys []any) {
+	sort.Slice(keys, func(i, j int) bool {
+		l := reflect.ValueOf(keys[i])
+		r := reflect.ValueOf(keys[j])
+		switch {
+		case l.Kind() != r.Kind():
+			return l.Kind() < r.Kind()
+		case l.CanInt():
+			return l.Int() < r.Int()
+		case l.CanUint():
+			return l.Uint() < r.Uint()
+		case l.CanFloat():
+			return l.Float() < r.Float()
+		case l.Kind() == reflect.String:
+			return l.String() < r.String()
+		default:
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		}
+	})
+}
+
+// splitPath returns the unescaped segments of a request path
+func splitPath(u *url.URL) ([]string, error) {
+	p := strings.Trim(u.EscapedPath(), "/")
+	if p == "" {
+		return nil, nil
+	}
+	res := strings.Split(p, "/")
+	for i, s := range res {
+		var err error
+		if res[i], err = url.PathUnescape(s); err != nil {
+			return nil, badRequest(err)
+		}
+	}
+	return res, nil
+}
+
+func intParam(params url.Values, name string, def int) (int, error) {
+	s := params.Get(name)
+	if s == "" {
+		return def, nil
+	}
+	res, err := strconv.Atoi(s)
+	if err != nil || res < 0 {
+		return 0, badRequest(fmt.Errorf(ErrInvalidParameter, name, s))
+	}
+	return res, nil
+}
+
+func notFound(err error) error {
+	return &statusError{
+		status: http.StatusNotFound,
+		error:  err,
+	}
+}
+
+func badRequest(err error) error {
+	return &statusError{
+		status: http.StatusBadRequest,
+		error:  err,
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if s, ok := err.(*statusError); ok {
+		status = s.status
+	}
+	writeJSON(w, status, &Error{Error: err.Error()})
+}
+
+// writeJSON marshals the value before writing anything, so that a value that
+// can't be marshaled, such as a row holding a channel, is reported as an
+// Internal Server Error rather than as a truncated response
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		status = http.StatusInternalServerError
+		body, _ = json.Marshal(&Error{Error: err.Error()})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	// A failed write means the client has gone away
+	_, _ = w.Write(append(body, '\n'))
+}
@@ -0,0 +1,199 @@
+package query_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/caravan/streaming"
+	"github.com/caravan/streaming/table"
+	"github.com/caravan/streaming/table/query"
+	"github.com/stretchr/testify/assert"
+)
+
+func get(t *testing.T, h http.Handler, path string, res any) int {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), res))
+	return rec.Code
+}
+
+func keysOf(m map[string]any) []string {
+	res := make([]string, 0, len(m))
+	for k := range m {
+		res = append(res, k)
+	}
+	return res
+}
+
+func makeHandler(t *testing.T) *query.Handler {
+	as := assert.New(t)
+
+	users, err := streaming.NewTable[string, any]("name", "age")
+	as.Nil(err)
+	set, err := users.Setter("name", "age")
+	as.Nil(err)
+	as.Nil(set("a/1", "Bill", 42))
+	as.Nil(set("b", "Jane", 37))
+
+	counts, err := streaming.NewTable[int, int]("count")
+	as.Nil(err)
+	setCount, err := counts.Setter("count")
+	as.Nil(err)
+	for i := 0; i < 15; i++ {
+		as.Nil(setCount(i, i*10))
+	}
+
+	h := query.NewHandler()
+	as.Nil(query.Register(h, "users", users, nil))
+	as.Nil(query.Register(h, "counts", counts, strconv.Atoi))
+	return h
+}
+
+func TestRegister(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := streaming.NewTable[string, any]("name")
+	as.Nil(err)
+	h := query.NewHandler()
+	as.Nil(query.Register(h, "users", tbl, nil))
+	as.EqualError(query.Register(h, "users", tbl, nil),
+		fmt.Sprintf(query.ErrAlreadyRegistered, "users"),
+	)
+	as.EqualError(query.Register(h, "", tbl, nil), query.ErrNameRequired)
+	as.EqualError(query.Register(h, "a/b", tbl, nil),
+		fmt.Sprintf(query.ErrNameInvalid, "a/b"),
+	)
+
+	h.Unregister("users")
+	as.Nil(query.Register(h, "users", tbl, nil))
+}
+
+func TestSummariesAndStats(t *testing.T) {
+	as := assert.New(t)
+	h := makeHandler(t)
+
+	var summaries []query.Summary
+	as.Equal(http.StatusOK, get(t, h, "/", &summaries))
+	as.Equal([]query.Summary{
+		{Name: "counts", Columns: []table.ColumnName{"count"}},
+		{Name: "users", Columns: []table.ColumnName{"name", "age"}},
+	}, summaries)
+
+	var stats query.Stats
+	as.Equal(http.StatusOK, get(t, h, "/users/stats", &stats))
+	as.Equal("users", stats.Name)
+	as.Equal(2, stats.Rows)
+	as.Greater(stats.Bytes, int64(0))
+	as.Equal(query.ColumnStats{Writes: 2}, stats.Usage["name"])
+
+	var row query.Row
+	as.Equal(http.StatusOK, get(t, h, "/users/rows/b?columns=name", &row))
+	var e query.Error
+	as.Equal(http.StatusNotFound, get(t, h, "/users/rows/c", &e))
+	as.Equal(http.StatusOK, get(t, h, "/users/stats", &stats))
+	as.Equal(query.ColumnStats{Reads: 1, Writes: 2}, stats.Usage["name"])
+	as.Equal(query.Lookups{Hits: 1, Misses: 1}, stats.Lookups)
+	as.Equal(0.5, stats.MissRate)
+
+	// Every key of the response uses the same casing
+	var raw map[string]any
+	as.Equal(http.StatusOK, get(t, h, "/users/stats", &raw))
+	as.ElementsMatch([]string{
+		"name", "columns", "rows", "bytes", "usage", "lookups", "miss_rate",
+		"lock_wait",
+	}, keysOf(raw))
+	as.Equal(map[string]any{"reads": 1.0, "writes": 2.0},
+		raw["usage"].(map[string]any)["name"],
+	)
+	as.Equal(map[string]any{"hits": 1.0, "misses": 1.0}, raw["lookups"])
+
+	as.Equal(http.StatusNotFound, get(t, h, "/missing/stats", &e))
+	as.Equal(fmt.Sprintf(query.ErrTableNotFound, "missing"), e.Error)
+	as.Equal(http.StatusNotFound, get(t, h, "/users/other", &e))
+	as.Equal(fmt.Sprintf(query.ErrPathNotFound, "/users/other"), e.Error)
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+	as.Equal(http.StatusMethodNotAllowed, rec.Code)
+	as.Equal("GET, HEAD", rec.Header().Get("Allow"))
+}
+
+func TestKeys(t *testing.T) {
+	as := assert.New(t)
+	h := makeHandler(t)
+
+	var keys query.Keys
+	as.Equal(http.StatusOK, get(t, h, "/counts/keys?offset=8&limit=5", &keys))
+	as.Equal(query.Keys{
+		Keys:   []any{8.0, 9.0, 10.0, 11.0, 12.0},
+		Offset: 8,
+		Limit:  5,
+		Total:  15,
+	}, keys)
+
+	as.Equal(http.StatusOK, get(t, h, "/counts/keys?offset=20", &keys))
+	as.Equal(query.Keys{
+		Keys:   []any{},
+		Offset: 20,
+		Limit:  query.DefaultLimit,
+		Total:  15,
+	}, keys)
+
+	as.Equal(http.StatusOK, get(t, h, "/users/keys", &keys))
+	as.Equal([]any{"a/1", "b"}, keys.Keys)
+
+	var e query.Error
+	as.Equal(http.StatusBadRequest, get(t, h, "/counts/keys?limit=0", &e))
+	as.Equal(fmt.Sprintf(query.ErrInvalidParameter, "limit", "0"), e.Error)
+	as.Equal(http.StatusBadRequest, get(t, h, "/counts/keys?offset=x", &e))
+	as.Equal(fmt.Sprintf(query.ErrInvalidParameter, "offset", "x"), e.Error)
+}
+
+func TestRows(t *testing.T) {
+	as := assert.New(t)
+	h := makeHandler(t)
+
+	var row query.Row
+	as.Equal(http.StatusOK, get(t, h, "/users/rows/a%2F1", &row))
+	as.Equal(query.Row{
+		Key:    "a/1",
+		Values: map[table.ColumnName]any{"name": "Bill", "age": 42.0},
+	}, row)
+
+	row = query.Row{}
+	as.Equal(http.StatusOK, get(t, h, "/counts/rows/3?columns=count", &row))
+	as.Equal(query.Row{
+		Key:    3.0,
+		Values: map[table.ColumnName]any{"count": 30.0},
+	}, row)
+
+	var e query.Error
+	as.Equal(http.StatusNotFound, get(t, h, "/users/rows/c", &e))
+	as.Equal(fmt.Sprintf(table.ErrKeyNotFound, "c"), e.Error)
+	as.Equal(http.StatusBadRequest, get(t, h, "/users/rows/b?columns=x", &e))
+	as.Equal(fmt.Sprintf(table.ErrColumnNotFound, "x"), e.Error)
+
+	as.Equal(http.StatusBadRequest, get(t, h, "/counts/rows/x", &e))
+	_, err := strconv.Atoi("x")
+	as.Equal(fmt.Sprintf(query.ErrInvalidKey, "x", err), e.Error)
+}
+
+func TestUnmarshalableRow(t *testing.T) {
+	as := assert.New(t)
+
+	tbl, err := streaming.NewTable[string, any]("events")
+	as.Nil(err)
+	set, _ := tbl.Setter("events")
+	as.Nil(set("a", make(chan int)))
+	h := query.NewHandler()
+	as.Nil(query.Register(h, "streams", tbl, nil))
+
+	var res query.Error
+	as.Equal(http.StatusInternalServerError, get(t, h, "/streams/rows/a", &res))
+	as.Contains(res.Error, "unsupported type")
+}
@@ -0,0 +1,6 @@
+package table
+
+// Reducer merges a newly written Value into a column's existing Value,
+// returning the Value that the column should hold. A Table calls it while
+// holding its lock, so concurrent writers never lose each other's updates
+type Reducer[Value any] func(old, new Value) (Value, error)
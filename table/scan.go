@@ -0,0 +1,82 @@
+package table
+
+import "sort"
+
+type (
+	// Scanner is a function that is capable of visiting a pre-defined set of
+	// column Values for every row in a Table. The rows are captured as a
+	// consistent point-in-time snapshot before any of them are visited, so
+	// the Visitor is free to interact with the Table. If the rows can't be
+	// captured, none are visited and the error is returned
+	Scanner[Key comparable, Value any] func(Visitor[Key, Value]) error
+
+	// Visitor is called by a Scanner for each row it visits. Returning false
+	// stops the scan
+	Visitor[Key comparable, Value any] func(Key, []Value) bool
+
+	// RowPredicate is used to filter the rows visited by a Scanner. It is
+	// provided the Key and the Scanner's selected column Values
+	RowPredicate[Key comparable, Value any] func(Key, []Value) bool
+
+	// Ordered is a constraint that permits any type whose values can be
+	// compared using the < operator
+	Ordered interface {
+		~int | ~int8 | ~int16 | ~int32 | ~int64 |
+			~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+			~float32 | ~float64 | ~string
+	}
+)
+
+// Where returns a Scanner that only visits the rows for which the provided
+// RowPredicate returns true
+func (s Scanner[Key, Value]) Where(
+	p RowPredicate[Key, Value],
+) Scanner[Key, Value] {
+	return func(v Visitor[Key, Value]) error {
+		return s(func(k Key, row []Value) bool {
+			if !p(k, row) {
+				return true
+			}
+			return v(k, row)
+		})
+	}
+}
+
+// Range returns a Scanner that visits, in Key order, the rows whose Keys fall
+// within the half-open interval [from, to)
+func Range[Key Ordered, Value any](
+	s Scanner[Key, Value], from, to Key,
+) Scanner[Key, Value] {
+	return Sorted(s.Where(func(k Key, _ []Value) bool {
+		return k >= from && k < to
+	}))
+}
+
+// Sorted returns a Scanner that visits its rows in Key order
+func Sorted[Key Ordered, Value any](
+	s Scanner[Key, Value],
+) Scanner[Key, Value] {
+	return func(v Visitor[Key, Value]) error {
+		type entry struct {
+			key Key
+			row []Value
+		}
+		var entries []entry
+		err := s(func(k Key, row []Value) bool {
+			entries = append(entries, entry{k, row})
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].key < entries[j].key
+		})
+		for _, e := range entries {
+			if !v(e.key, e.row) {
+				break
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,60 @@
+package table
+
+import "time"
+
+type (
+	// Stats reports the size of a Table and how it has been used. Counts
+	// accumulate from the moment the Table is created, and aren't persisted
+	Stats struct {
+		// Rows is the number of rows currently in the Table
+		Rows int
+
+		// Bytes estimates the memory taken up by the Table's rows,
+		// including their Keys. It doesn't account for allocator overhead,
+		// or for memory that's shared between rows. The estimate is kept
+		// up to date as rows are written, so reporting it is cheap. A Table
+		// whose rows are kept on disk only reports the memory taken up by
+		// its Keys
+		Bytes int64
+
+		// Columns counts the reads and writes of each column
+		Columns map[ColumnName]ColumnStats
+
+		// Lookups counts the Keys looked up by the Table's Getters,
+		// MultiGetters, VersionedGetters, and PresenceGetters. Lookups made
+		// by a Transaction aren't counted
+		Lookups Lookups
+
+		// LockWait is the total time spent waiting to acquire the Table's
+		// lock, by readers and writers alike
+		LockWait time.Duration
+	}
+
+	// ColumnStats counts the reads and writes of a column. Every row whose
+	// Value in the column is retrieved counts as a read, whether by a
+	// Getter, a Scanner, or any other closure, and every row whose Value in
+	// the column is set counts as a write. Writes made by a Transaction are
+	// only counted once it's committed
+	ColumnStats struct {
+		Reads  uint64
+		Writes uint64
+	}
+
+	// Lookups counts the Keys looked up by a Table's Getters and the other
+	// closures that read a row by its Key. A Table used as a cache can
+	// derive its hit rate from them
+	Lookups struct {
+		Hits   uint64
+		Misses uint64
+	}
+)
+
+// MissRate returns the fraction of lookups for which no row was found, or zero
+// if there haven't been any lookups
+func (l Lookups) MissRate() float64 {
+	total := l.Hits + l.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(l.Misses) / float64(total)
+}
@@ -0,0 +1,287 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+type (
+	// Limits bound the contents of a Store. Rows is the most rows it may
+	// hold, and Bytes is the most memory, as estimated from the size of each
+	// row's Key and Values. A zero limit isn't enforced
+	Limits struct {
+		Rows  int
+		Bytes int64
+	}
+
+	// Policy chooses the rows that a bounded Store evicts to make room
+	Policy uint8
+
+	// bounded is an in-memory Store that evicts rows according to its
+	// Policy once one of its Limits is reached. Because Get counts as a
+	// use, and may be called concurrently, the Store has its own lock
+	bounded[Key comparable, Value any] struct {
+		sync.Mutex
+		limits  Limits
+		policy  policy[Key]
+		rows    map[Key]*boundedEntry[Value]
+		bytes   int64
+		onEvict EvictHandler[Key, Value]
+	}
+
+	boundedEntry[Value any] struct {
+		row  []Value
+		size int64
+	}
+
+	// policy orders Keys by how willing a bounded Store is to evict them
+	policy[Key comparable] interface {
+		add(Key)
+		use(Key)
+		remove(Key)
+		each(func(Key) bool)
+	}
+)
+
+// Policies
+const (
+	// LeastRecentlyUsed evicts the rows that have gone the longest without
+	// being read or written. This is the default Policy
+	LeastRecentlyUsed Policy = iota + 1
+
+	// LeastFrequentlyUsed evicts the rows that have been read or written
+	// the fewest times, and the least recently used of those
+	LeastFrequentlyUsed
+)
+
+// Error messages
+const (
+	ErrInvalidCapacity = "capacity must be greater than zero: %d"
+	ErrInvalidLimits   = "limits can't be negative: %+v"
+	ErrLimitRequired   = "a row or memory limit is required"
+	ErrInvalidPolicy   = "unknown eviction policy: %d"
+)
+
+// LRU returns a Factory for Stores that hold at most the specified number of
+// rows in memory, evicting the least recently used row to make room for a new
+// one
+func LRU[Key comparable, Value any](capacity int) Factory[Key, Value] {
+	return func(int) (Store[Key, Value], error) {
+		return NewLRU[Key, Value](capacity)
+	}
+}
+
+// NewLRU creates a Store that holds at most the specified number of rows in
+// memory, evicting the least recently used row to make room for a new one
+func NewLRU[Key comparable, Value any](
+	capacity int,
+) (Store[Key, Value], error) {
+	if capacity < 1 {
+		return nil, fmt.Errorf(ErrInvalidCapacity, capacity)
+	}
+	return NewBounded[Key, Value](
+		Limits{Rows: capacity}, LeastRecentlyUsed,
+	)
+}
+
+// LFU returns a Factory for Stores that hold at most the specified number of
+// rows in memory, evicting the least frequently used row to make room for a
+// new one
+func LFU[Key comparable, Value any](capacity int) Factory[Key, Value] {
+	return func(int) (Store[Key, Value], error) {
+		return NewLFU[Key, Value](capacity)
+	}
+}
+
+// NewLFU creates a Store that holds at most the specified number of rows in
+// memory, evicting the least frequently used row to make room for a new one
+func NewLFU[Key comparable, Value any](
+	capacity int,
+) (Store[Key, Value], error) {
+	if capacity < 1 {
+		return nil, fmt.Errorf(ErrInvalidCapacity, capacity)
+	}
+	return NewBounded[Key, Value](
+		Limits{Rows: capacity}, LeastFrequentlyUsed,
+	)
+}
+
+// Bounded returns a Factory for Stores that hold rows in memory within the
+// specified Limits, evicting rows according to the Policy to stay within them
+func Bounded[Key comparable, Value any](
+	l Limits, p Policy,
+) Factory[Key, Value] {
+	return func(int) (Store[Key, Value], error) {
+		return NewBounded[Key, Value](l, p)
+	}
+}
+
+// NewBounded creates a Store that holds rows in memory within the specified
+// Limits, evicting rows according to the Policy to stay within them. A row
+// that's larger than the memory limit on its own is still kept, but every
+// other row is evicted to make room for it. The zero Policy is
+// LeastRecentlyUsed
+func NewBounded[Key comparable, Value any](
+	l Limits, p Policy,
+) (Store[Key, Value], error) {
+	if l.Rows < 0 || l.Bytes < 0 {
+		return nil, fmt.Errorf(ErrInvalidLimits, l)
+	}
+	if l.Rows == 0 && l.Bytes == 0 {
+		return nil, errors.New(ErrLimitRequired)
+	}
+	res := &bounded[Key, Value]{
+		limits: l,
+		rows:   map[Key]*boundedEntry[Value]{},
+	}
+	switch p {
+	case 0, LeastRecentlyUsed:
+		res.policy = makeLRU[Key]()
+	case LeastFrequentlyUsed:
+		res.policy = makeLFU[Key]()
+	default:
+		return nil, fmt.Errorf(ErrInvalidPolicy, p)
+	}
+	return res, nil
+}
+
+func (s *bounded[Key, Value]) OnEvict(h EvictHandler[Key, Value]) {
+	s.onEvict = h
+}
+
+func (s *bounded[Key, Value]) Get(k Key) ([]Value, bool, error) {
+	s.Lock()
+	defer s.Unlock()
+	if e, ok := s.rows[k]; ok {
+		s.policy.use(k)
+		return e.row, true, nil
+	}
+	return nil, false, nil
+}
+
+// Peek returns the row of the Key without counting as a use of it
+func (s *bounded[Key, Value]) Peek(k Key) ([]Value, bool, error) {
+	s.Lock()
+	defer s.Unlock()
+	if e, ok := s.rows[k]; ok {
+		return e.row, true, nil
+	}
+	return nil, false, nil
+}
+
+func (s *bounded[Key, Value]) Put(k Key, row []Value) error {
+	s.Lock()
+	size := SizeOf(k, row)
+	if e, ok := s.rows[k]; ok {
+		s.bytes += size - e.size
+		e.row, e.size = row, size
+		s.policy.use(k)
+	} else {
+		s.rows[k] = &boundedEntry[Value]{
+			row:  row,
+			size: size,
+		}
+		s.bytes += size
+		s.policy.add(k)
+	}
+	evicted := s.evict(k)
+	s.Unlock()
+
+	if s.onEvict != nil {
+		for _, k := range evicted {
+			s.onEvict(k.key, k.row)
+		}
+	}
+	return nil
+}
+
+// evict removes rows, other than the one being kept, until the Store is
+// within its Limits. The caller must hold the lock
+func (s *bounded[Key, Value]) evict(keep Key) []*evictedRow[Key, Value] {
+	rows, bytes := len(s.rows), s.bytes
+	var victims []Key
+	s.policy.each(func(k Key) bool {
+		if !s.over(rows, bytes) {
+			return false
+		}
+		if k != keep {
+			victims = append(victims, k)
+			rows--
+			bytes -= s.rows[k].size
+		}
+		return true
+	})
+
+	res := make([]*evictedRow[Key, Value], len(victims))
+	for i, k := range victims {
+		res[i] = &evictedRow[Key, Value]{
+			key: k,
+			row: s.rows[k].row,
+		}
+		s.remove(k)
+	}
+	return res
+}
+
+func (s *bounded[_, _]) over(rows int, bytes int64) bool {
+	l := s.limits
+	return (l.Rows != 0 && rows > l.Rows) || (l.Bytes != 0 && bytes > l.Bytes)
+}
+
+func (s *bounded[Key, _]) Remove(k Key) error {
+	s.Lock()
+	defer s.Unlock()
+	if _, ok := s.rows[k]; ok {
+		s.remove(k)
+	}
+	return nil
+}
+
+func (s *bounded[Key, _]) remove(k Key) {
+	s.bytes -= s.rows[k].size
+	delete(s.rows, k)
+	s.policy.remove(k)
+}
+
+func (s *bounded[_, _]) Len() int {
+	s.Lock()
+	defer s.Unlock()
+	return len(s.rows)
+}
+
+// Bytes returns the estimated memory taken up by the rows in the Store
+func (s *bounded[_, _]) Bytes() int64 {
+	s.Lock()
+	defer s.Unlock()
+	return s.bytes
+}
+
+// Each visits rows without counting as a use of them
+func (s *bounded[Key, Value]) Each(fn func(Key, []Value) bool) error {
+	s.Lock()
+	rows := make([]*evictedRow[Key, Value], 0, len(s.rows))
+	for k, e := range s.rows {
+		rows = append(rows, &evictedRow[Key, Value]{
+			key: k,
+			row: e.row,
+		})
+	}
+	s.Unlock()
+
+	for _, r := range rows {
+		if !fn(r.key, r.row) {
+			break
+		}
+	}
+	return nil
+}
+
+func (s *bounded[_, _]) Close() error {
+	return nil
+}
+
+type evictedRow[Key comparable, Value any] struct {
+	key Key
+	row []Value
+}
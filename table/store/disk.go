@@ -0,0 +1,300 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/caravan/streaming/table/codec"
+)
+
+type (
+	// diskStore is a Store that keeps rows in a local file. The file is an
+	// append-only log of records, and only the location of each Key's
+	// latest record is kept in memory. Once enough of the file is taken up
+	// by superseded records, it's compacted
+	diskStore[Key comparable, Value any] struct {
+		path    string
+		codec   codec.Codec
+		file    *os.File
+		offsets map[Key]location
+		size    int64
+		dead    int64
+	}
+
+	// location is where a record's frame starts in the file, and how long
+	// the frame is, including its header
+	location struct {
+		offset int64
+		length int64
+	}
+
+	diskRecord[Key comparable, Value any] struct {
+		Key     Key
+		Values  []Value
+		Removed bool
+	}
+)
+
+const (
+	// frameHeaderSize is the size of the length that precedes each record
+	frameHeaderSize = 4
+
+	// compactMinBytes is how many bytes superseded records must take up
+	// before the file is considered for compaction
+	compactMinBytes = 1 << 20
+)
+
+// Disk returns a Factory for Stores that keep rows in files within the
+// specified directory, using the provided Codec to encode them. Each shard
+// gets its own file. Only Keys are held in memory, so a Table can hold more
+// rows than would fit in RAM. Rows in the files are visible to a Table as soon
+// as it's constructed
+func Disk[Key comparable, Value any](
+	dir string, c codec.Codec,
+) Factory[Key, Value] {
+	return func(shard int) (Store[Key, Value], error) {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+		path := filepath.Join(dir, fmt.Sprintf("%d.rows", shard))
+		return OpenDisk[Key, Value](path, c)
+	}
+}
+
+// OpenDisk opens a Store that keeps rows in the specified file, using the
+// provided Codec to encode them. If the file doesn't exist, it's created. A
+// partially written record at the end of the file, such as one left by a
+// crash, is discarded
+func OpenDisk[Key comparable, Value any](
+	path string, c codec.Codec,
+) (Store[Key, Value], error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	s := &diskStore[Key, Value]{
+		path:    path,
+		codec:   c,
+		file:    f,
+		offsets: map[Key]location{},
+	}
+	if err := s.load(); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *diskStore[Key, Value]) load() error {
+	r := bufio.NewReader(s.file)
+	for {
+		frame, err := readFrame(r)
+		if err == io.EOF {
+			return nil
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return s.file.Truncate(s.size)
+		}
+		if err != nil {
+			return err
+		}
+		rec, err := s.decode(frame)
+		if err != nil {
+			return err
+		}
+		s.track(rec, int64(len(frame)))
+	}
+}
+
+func (s *diskStore[Key, Value]) Get(k Key) ([]Value, bool, error) {
+	loc, ok := s.offsets[k]
+	if !ok {
+		return nil, false, nil
+	}
+	rec, err := s.read(loc)
+	if err != nil {
+		return nil, false, err
+	}
+	return rec.Values, true, nil
+}
+
+func (s *diskStore[Key, Value]) Put(k Key, row []Value) error {
+	return s.write(&diskRecord[Key, Value]{
+		Key:    k,
+		Values: row,
+	})
+}
+
+func (s *diskStore[Key, Value]) Remove(k Key) error {
+	if _, ok := s.offsets[k]; !ok {
+		return nil
+	}
+	return s.write(&diskRecord[Key, Value]{
+		Key:     k,
+		Removed: true,
+	})
+}
+
+func (s *diskStore[_, _]) Len() int {
+	return len(s.offsets)
+}
+
+func (s *diskStore[Key, Value]) Each(fn func(Key, []Value) bool) error {
+	for k, loc := range s.offsets {
+		rec, err := s.read(loc)
+		if err != nil {
+			return err
+		}
+		if !fn(k, rec.Values) {
+			break
+		}
+	}
+	return nil
+}
+
+// Close flushes the file to disk and closes it
+func (s *diskStore[_, _]) Close() error {
+	err := s.file.Sync()
+	if cErr := s.file.Close(); err == nil {
+		err = cErr
+	}
+	return err
+}
+
+func (s *diskStore[Key, Value]) write(rec *diskRecord[Key, Value]) error {
+	var buf bytes.Buffer
+	buf.Write(make([]byte, frameHeaderSize))
+	if err := s.codec.NewEncoder(&buf).Encode(rec); err != nil {
+		return err
+	}
+	frame := buf.Bytes()
+	binary.BigEndian.PutUint32(frame, uint32(len(frame)-frameHeaderSize))
+	if _, err := s.file.WriteAt(frame, s.size); err != nil {
+		return err
+	}
+	s.track(rec, int64(len(frame)))
+	if s.dead >= compactMinBytes && s.dead > s.size/2 {
+		return s.compact()
+	}
+	return nil
+}
+
+// track records the location of a frame that was just appended to the file
+func (s *diskStore[Key, Value]) track(rec *diskRecord[Key, Value], n int64) {
+	if old, ok := s.offsets[rec.Key]; ok {
+		s.dead += old.length
+	}
+	if rec.Removed {
+		delete(s.offsets, rec.Key)
+		s.dead += n
+	} else {
+		s.offsets[rec.Key] = location{
+			offset: s.size,
+			length: n,
+		}
+	}
+	s.size += n
+}
+
+func (s *diskStore[Key, Value]) read(
+	loc location,
+) (*diskRecord[Key, Value], error) {
+	frame := make([]byte, loc.length)
+	if _, err := s.file.ReadAt(frame, loc.offset); err != nil {
+		return nil, err
+	}
+	return s.decode(frame)
+}
+
+func (s *diskStore[Key, Value]) decode(
+	frame []byte,
+) (*diskRecord[Key, Value], error) {
+	var rec diskRecord[Key, Value]
+	payload := bytes.NewReader(frame[frameHeaderSize:])
+	if err := s.codec.NewDecoder(payload).Decode(&rec); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// compact rewrites the file so that it only contains the latest record of
+// each Key. The records are copied to a temporary file first, so that a
+// failure never clobbers the current one
+func (s *diskStore[Key, Value]) compact() error {
+	tmp := s.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	offsets := make(map[Key]location, len(s.offsets))
+	var size int64
+	err = func() error {
+		w := bufio.NewWriter(f)
+		for k, loc := range s.offsets {
+			frame := make([]byte, loc.length)
+			if _, err := s.file.ReadAt(frame, loc.offset); err != nil {
+				return err
+			}
+			if _, err := w.Write(frame); err != nil {
+				return err
+			}
+			offsets[k] = location{
+				offset: size,
+				length: loc.length,
+			}
+			size += loc.length
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		return f.Sync()
+	}()
+	if err == nil {
+		err = os.Rename(tmp, s.path)
+	}
+	if err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	_ = s.file.Close()
+	s.file = f
+	s.offsets = offsets
+	s.size = size
+	s.dead = 0
+	return nil
+}
+
+func readFrame(r io.Reader) ([]byte, error) {
+	header := make([]byte, frameHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(header)
+	frame := make([]byte, frameHeaderSize+int(length))
+	copy(frame, header)
+	if _, err := io.ReadFull(r, frame[frameHeaderSize:]); err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return frame, nil
+}
+
+// Bytes returns the estimated memory taken up by the Keys held in memory, and
+// by the locations of their records
+func (s *diskStore[Key, Value]) Bytes() int64 {
+	res := int64(len(s.offsets)) * int64(reflect.TypeOf(location{}).Size())
+	for k := range s.offsets {
+		res += sizeOfValue(reflect.ValueOf(&k).Elem(), 0)
+	}
+	return res
+}
@@ -0,0 +1,49 @@
+package store
+
+// mapStore is the default Store, keeping rows in memory in no particular order
+type mapStore[Key comparable, Value any] map[Key][]Value
+
+// Map returns a Factory for Stores that keep rows in an unbounded in-memory
+// map. This is the Store a Table uses by default
+func Map[Key comparable, Value any]() Factory[Key, Value] {
+	return func(int) (Store[Key, Value], error) {
+		return NewMap[Key, Value](), nil
+	}
+}
+
+// NewMap creates a Store that keeps rows in an unbounded in-memory map
+func NewMap[Key comparable, Value any]() Store[Key, Value] {
+	return mapStore[Key, Value]{}
+}
+
+func (s mapStore[Key, Value]) Get(k Key) ([]Value, bool, error) {
+	row, ok := s[k]
+	return row, ok, nil
+}
+
+func (s mapStore[Key, Value]) Put(k Key, row []Value) error {
+	s[k] = row
+	return nil
+}
+
+func (s mapStore[Key, _]) Remove(k Key) error {
+	delete(s, k)
+	return nil
+}
+
+func (s mapStore[_, _]) Len() int {
+	return len(s)
+}
+
+func (s mapStore[Key, Value]) Each(fn func(Key, []Value) bool) error {
+	for k, row := range s {
+		if !fn(k, row) {
+			break
+		}
+	}
+	return nil
+}
+
+func (s mapStore[_, _]) Close() error {
+	return nil
+}
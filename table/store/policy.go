@@ -0,0 +1,119 @@
+package store
+
+import "container/list"
+
+type (
+	// lruPolicy keeps Keys in the order they were last used, with the most
+	// recently used at the front
+	lruPolicy[Key comparable] struct {
+		order    *list.List
+		elements map[Key]*list.Element
+	}
+
+	// lfuPolicy keeps Keys in buckets of the same use count, ordered from
+	// the least to the most used. Within a bucket, the most recently used
+	// Key is at the front
+	lfuPolicy[Key comparable] struct {
+		buckets *list.List
+		items   map[Key]*lfuItem
+	}
+
+	lfuBucket struct {
+		uses int
+		keys *list.List
+	}
+
+	lfuItem struct {
+		bucket  *list.Element
+		element *list.Element
+	}
+)
+
+func makeLRU[Key comparable]() *lruPolicy[Key] {
+	return &lruPolicy[Key]{
+		order:    list.New(),
+		elements: map[Key]*list.Element{},
+	}
+}
+
+func (p *lruPolicy[Key]) add(k Key) {
+	p.elements[k] = p.order.PushFront(k)
+}
+
+func (p *lruPolicy[Key]) use(k Key) {
+	p.order.MoveToFront(p.elements[k])
+}
+
+func (p *lruPolicy[Key]) remove(k Key) {
+	p.order.Remove(p.elements[k])
+	delete(p.elements, k)
+}
+
+func (p *lruPolicy[Key]) each(fn func(Key) bool) {
+	for e := p.order.Back(); e != nil; e = e.Prev() {
+		if !fn(e.Value.(Key)) {
+			return
+		}
+	}
+}
+
+func makeLFU[Key comparable]() *lfuPolicy[Key] {
+	return &lfuPolicy[Key]{
+		buckets: list.New(),
+		items:   map[Key]*lfuItem{},
+	}
+}
+
+func (p *lfuPolicy[Key]) add(k Key) {
+	first := p.buckets.Front()
+	if first == nil || first.Value.(*lfuBucket).uses != 1 {
+		first = p.buckets.PushFront(&lfuBucket{
+			uses: 1,
+			keys: list.New(),
+		})
+	}
+	p.items[k] = &lfuItem{
+		bucket:  first,
+		element: first.Value.(*lfuBucket).keys.PushFront(k),
+	}
+}
+
+func (p *lfuPolicy[Key]) use(k Key) {
+	item := p.items[k]
+	current := item.bucket.Value.(*lfuBucket)
+	next := item.bucket.Next()
+	if next == nil || next.Value.(*lfuBucket).uses != current.uses+1 {
+		next = p.buckets.InsertAfter(&lfuBucket{
+			uses: current.uses + 1,
+			keys: list.New(),
+		}, item.bucket)
+	}
+	p.unlink(item)
+	item.bucket = next
+	item.element = next.Value.(*lfuBucket).keys.PushFront(k)
+}
+
+func (p *lfuPolicy[Key]) remove(k Key) {
+	p.unlink(p.items[k])
+	delete(p.items, k)
+}
+
+// unlink removes an item from its bucket, and the bucket if it's left empty
+func (p *lfuPolicy[Key]) unlink(item *lfuItem) {
+	b := item.bucket.Value.(*lfuBucket)
+	b.keys.Remove(item.element)
+	if b.keys.Len() == 0 {
+		p.buckets.Remove(item.bucket)
+	}
+}
+
+func (p *lfuPolicy[Key]) each(fn func(Key) bool) {
+	for b := p.buckets.Front(); b != nil; b = b.Next() {
+		keys := b.Value.(*lfuBucket).keys
+		for e := keys.Back(); e != nil; e = e.Prev() {
+			if !fn(e.Value.(Key)) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package store
+
+import "reflect"
+
+// maxSizeDepth limits how far SizeOf follows pointers, interfaces and
+// containers, which also keeps it from looping on cyclic structures
+const maxSizeDepth = 8
+
+// SizeOf estimates the memory taken up by a row, including its Key. The
+// estimate counts the headers and contents of strings, slices and maps, and
+// follows pointers, but it doesn't account for allocator overhead or for
+// memory that's shared between rows
+func SizeOf[Key comparable, Value any](k Key, row []Value) int64 {
+	res := sizeOfValue(reflect.ValueOf(&k).Elem(), 0)
+	res += sizeOfValue(reflect.ValueOf(row), 0)
+	return res
+}
+
+func sizeOfValue(v reflect.Value, depth int) int64 {
+	if !v.IsValid() {
+		return 0
+	}
+	res := int64(v.Type().Size())
+	if depth >= maxSizeDepth {
+		return res
+	}
+	return res + sizeOfReferenced(v, depth+1)
+}
+
+// sizeOfReferenced estimates the memory that a value refers to, excluding the
+// value itself
+func sizeOfReferenced(v reflect.Value, depth int) int64 {
+	switch v.Kind() {
+	case reflect.String:
+		return int64(v.Len())
+	case reflect.Slice:
+		if v.IsNil() {
+			return 0
+		}
+		res := int64(v.Cap()-v.Len()) * int64(v.Type().Elem().Size())
+		for i := 0; i < v.Len(); i++ {
+			res += sizeOfValue(v.Index(i), depth)
+		}
+		return res
+	case reflect.Array:
+		var res int64
+		for i := 0; i < v.Len(); i++ {
+			res += sizeOfReferenced(v.Index(i), depth)
+		}
+		return res
+	case reflect.Struct:
+		var res int64
+		for i := 0; i < v.NumField(); i++ {
+			res += sizeOfReferenced(v.Field(i), depth)
+		}
+		return res
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		return sizeOfValue(v.Elem(), depth)
+	case reflect.Map:
+		if v.IsNil() {
+			return 0
+		}
+		var res int64
+		it := v.MapRange()
+		for it.Next() {
+			res += sizeOfValue(it.Key(), depth)
+			res += sizeOfValue(it.Value(), depth)
+		}
+		return res
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,59 @@
+// Package store provides the storage backends that hold the rows of a Table
+package store
+
+type (
+	// Store holds the rows of a Table. A Store performs no locking of its
+	// own, the Table is responsible for that. Get and Each may be called
+	// concurrently with each other, but every other method is called
+	// exclusively
+	Store[Key comparable, Value any] interface {
+		// Get returns the row of the Key, if it exists
+		Get(Key) ([]Value, bool, error)
+
+		// Put adds or replaces the row of the Key
+		Put(Key, []Value) error
+
+		// Remove removes the row of the Key, if it exists
+		Remove(Key) error
+
+		// Len returns the number of rows in the Store
+		Len() int
+
+		// Each visits every row in the Store, in no particular order, until
+		// the visitor returns false
+		Each(func(Key, []Value) bool) error
+
+		// Close flushes the Store and releases the resources it holds, such
+		// as its files. It's called once, when the Table is closed
+		Close() error
+	}
+
+	// Evicting is implemented by a Store that removes rows on its own, such
+	// as one that's bounded. The Table registers an EvictHandler with the
+	// Store so that it can keep track of the rows that are removed
+	Evicting[Key comparable, Value any] interface {
+		OnEvict(EvictHandler[Key, Value])
+	}
+
+	// Sized is implemented by a Store that can report the memory taken up
+	// by its rows without the Table having to visit every one of them
+	Sized interface {
+		Bytes() int64
+	}
+
+	// Peeking is implemented by a Store whose Get counts as a use of a row,
+	// such as one that evicts the least used rows. The Table calls Peek
+	// instead of Get for its own reads, such as the one it makes before
+	// each write, so that only the reads of its users count
+	Peeking[Key comparable, Value any] interface {
+		Peek(Key) ([]Value, bool, error)
+	}
+
+	// EvictHandler is called by an Evicting Store, during a Put, for each
+	// row that it evicts
+	EvictHandler[Key comparable, Value any] func(Key, []Value)
+
+	// Factory creates the Store for a Table. A sharded Table calls it once
+	// for each of its shards, numbered from zero
+	Factory[Key comparable, Value any] func(shard int) (Store[Key, Value], error)
+)
@@ -0,0 +1,281 @@
+package store_test
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/caravan/streaming/table/codec"
+	"github.com/caravan/streaming/table/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func testStore(t *testing.T, s store.Store[string, any]) {
+	as := assert.New(t)
+
+	as.Nil(s.Put("1", []any{"bob", 42}))
+	as.Nil(s.Put("2", []any{"june", 36}))
+	as.Nil(s.Put("1", []any{"bob", 43}))
+	as.Equal(2, s.Len())
+
+	row, ok, err := s.Get("1")
+	as.Nil(err)
+	as.True(ok)
+	as.Equal([]any{"bob", 43}, row)
+
+	_, ok, err = s.Get("3")
+	as.Nil(err)
+	as.False(ok)
+
+	var keys []string
+	as.Nil(s.Each(func(k string, _ []any) bool {
+		keys = append(keys, k)
+		return true
+	}))
+	sort.Strings(keys)
+	as.Equal([]string{"1", "2"}, keys)
+
+	visited := 0
+	as.Nil(s.Each(func(string, []any) bool {
+		visited++
+		return false
+	}))
+	as.Equal(1, visited)
+
+	as.Nil(s.Remove("1"))
+	as.Nil(s.Remove("1"))
+	_, ok, _ = s.Get("1")
+	as.False(ok)
+	as.Equal(1, s.Len())
+	as.Nil(s.Close())
+}
+
+func TestMap(t *testing.T) {
+	s, err := store.Map[string, any]()(0)
+	assert.Nil(t, err)
+	testStore(t, s)
+}
+
+func TestLRU(t *testing.T) {
+	as := assert.New(t)
+
+	s, err := store.LRU[string, any](10)(0)
+	as.Nil(err)
+	testStore(t, s)
+
+	s, _ = store.NewLRU[string, any](2)
+	var evicted []string
+	s.(store.Evicting[string, any]).OnEvict(func(k string, row []any) {
+		evicted = append(evicted, k)
+		as.Equal([]any{k}, row)
+	})
+	as.Nil(s.Put("1", []any{"1"}))
+	as.Nil(s.Put("2", []any{"2"}))
+	_, _, _ = s.Get("1") // 2 is now the least recently used
+	as.Nil(s.Put("3", []any{"3"}))
+	as.Equal([]string{"2"}, evicted)
+	as.Nil(s.Put("1", []any{"1"})) // replacing doesn't evict
+	as.Nil(s.Put("4", []any{"4"}))
+	as.Equal([]string{"2", "3"}, evicted)
+	as.Equal(2, s.Len())
+
+	_, err = store.NewLRU[string, any](0)
+	as.EqualError(err, fmt.Sprintf(store.ErrInvalidCapacity, 0))
+	_, err = store.LRU[string, any](-1)(0)
+	as.EqualError(err, fmt.Sprintf(store.ErrInvalidCapacity, -1))
+}
+
+func TestDisk(t *testing.T) {
+	as := assert.New(t)
+
+	dir := t.TempDir()
+	s, err := store.Disk[string, any](dir, codec.Gob)(0)
+	as.Nil(err)
+	testStore(t, s)
+
+	// Rows survive reopening the file
+	s, err = store.Disk[string, any](dir, codec.Gob)(0)
+	as.Nil(err)
+	as.Equal(1, s.Len())
+	row, ok, err := s.Get("2")
+	as.Nil(err)
+	as.True(ok)
+	as.Equal([]any{"june", 36}, row)
+
+	// The file can't be read once the Store is closed
+	as.Nil(s.Close())
+	_, _, err = s.Get("2")
+	as.ErrorIs(err, os.ErrClosed)
+
+	// Each shard has its own file
+	s, err = store.Disk[string, any](dir, codec.Gob)(1)
+	as.Nil(err)
+	as.Equal(0, s.Len())
+}
+
+func TestDiskJSON(t *testing.T) {
+	as := assert.New(t)
+
+	path := t.TempDir() + "/rows"
+	s, err := store.OpenDisk[string, string](path, codec.JSON)
+	as.Nil(err)
+	as.Nil(s.Put("1", []string{"bob"}))
+
+	s, _ = store.OpenDisk[string, string](path, codec.JSON)
+	row, _, err := s.Get("1")
+	as.Nil(err)
+	as.Equal([]string{"bob"}, row)
+}
+
+func TestDiskTornTail(t *testing.T) {
+	as := assert.New(t)
+
+	path := t.TempDir() + "/rows"
+	s, _ := store.OpenDisk[string, any](path, codec.Gob)
+	as.Nil(s.Put("1", []any{"bob"}))
+	as.Nil(s.Put("2", []any{"june"}))
+
+	info, _ := os.Stat(path)
+	as.Nil(os.Truncate(path, info.Size()-3))
+
+	s, err := store.OpenDisk[string, any](path, codec.Gob)
+	as.Nil(err)
+	as.Equal(1, s.Len())
+	as.Nil(s.Put("3", []any{"carol"}))
+
+	s, _ = store.OpenDisk[string, any](path, codec.Gob)
+	as.Equal(2, s.Len())
+	row, _, _ := s.Get("3")
+	as.Equal([]any{"carol"}, row)
+}
+
+func TestDiskCorrupt(t *testing.T) {
+	as := assert.New(t)
+
+	path := t.TempDir() + "/rows"
+	as.Nil(os.WriteFile(path, []byte{0, 0, 0, 3, 'b', 'a', 'd'}, 0o644))
+	s, err := store.OpenDisk[string, any](path, codec.Gob)
+	as.Nil(s)
+	as.Error(err)
+}
+
+func TestDiskCompaction(t *testing.T) {
+	as := assert.New(t)
+
+	path := t.TempDir() + "/rows"
+	s, _ := store.OpenDisk[string, string](path, codec.Gob)
+	big := strings.Repeat("x", 4096)
+	for i := 0; i < 1000; i++ {
+		as.Nil(s.Put(fmt.Sprint(i%10), []string{big}))
+	}
+	info, _ := os.Stat(path)
+	as.Less(info.Size(), int64(1000*4096))
+
+	s, _ = store.OpenDisk[string, string](path, codec.Gob)
+	as.Equal(10, s.Len())
+	row, _, _ := s.Get("9")
+	as.Equal([]string{big}, row)
+}
+
+func TestLFU(t *testing.T) {
+	as := assert.New(t)
+
+	s, err := store.LFU[string, any](10)(0)
+	as.Nil(err)
+	testStore(t, s)
+
+	s, _ = store.NewLFU[string, any](2)
+	var evicted []string
+	s.(store.Evicting[string, any]).OnEvict(func(k string, _ []any) {
+		evicted = append(evicted, k)
+	})
+	as.Nil(s.Put("1", []any{"1"}))
+	as.Nil(s.Put("2", []any{"2"}))
+	_, _, _ = s.Get("1")
+	_, _, _ = s.Get("1")
+	_, _, _ = s.Get("2") // 1 has been used three times, 2 only twice
+	for i := 0; i < 5; i++ {
+		_, _, _ = s.(store.Peeking[string, any]).Peek("2")
+	}
+	as.Nil(s.Put("3", []any{"3"}))
+	as.Equal([]string{"2"}, evicted)
+
+	// Among rows used equally often, the least recently used goes first
+	_, _, _ = s.Get("3")
+	_, _, _ = s.Get("3") // 1 and 3 have both been used three times
+	as.Nil(s.Put("4", []any{"4"}))
+	as.Equal([]string{"2", "1"}, evicted)
+
+	_, err = store.NewLFU[string, any](0)
+	as.EqualError(err, fmt.Sprintf(store.ErrInvalidCapacity, 0))
+	_, err = store.LFU[string, any](-1)(0)
+	as.EqualError(err, fmt.Sprintf(store.ErrInvalidCapacity, -1))
+}
+
+func TestBoundedMemory(t *testing.T) {
+	as := assert.New(t)
+
+	s, err := store.NewBounded[string, any](
+		store.Limits{Bytes: 1024}, store.LeastRecentlyUsed,
+	)
+	as.Nil(err)
+	var evicted []string
+	s.(store.Evicting[string, any]).OnEvict(func(k string, _ []any) {
+		evicted = append(evicted, k)
+	})
+
+	row := []any{strings.Repeat("x", 250)}
+	as.Nil(s.Put("1", row))
+	as.Nil(s.Put("2", row))
+	as.Nil(s.Put("3", row))
+	as.Empty(evicted)
+	as.Nil(s.Put("4", row))
+	as.Equal([]string{"1"}, evicted)
+	as.Equal(3, s.Len())
+
+	// Growing a row evicts others, but never the row itself
+	as.Nil(s.Put("4", []any{strings.Repeat("x", 2048)}))
+	as.Equal([]string{"1", "2", "3"}, evicted)
+	_, ok, _ := s.Get("4")
+	as.True(ok)
+	as.Equal(1, s.Len())
+
+	as.Nil(s.Remove("4"))
+	as.Equal(0, s.Len())
+	as.Nil(s.Put("5", row))
+	as.Equal([]string{"1", "2", "3"}, evicted)
+}
+
+func TestBoundedRowsAndMemory(t *testing.T) {
+	as := assert.New(t)
+
+	s, err := store.Bounded[int, string](
+		store.Limits{Rows: 2, Bytes: 1 << 20}, 0,
+	)(0)
+	as.Nil(err)
+	as.Nil(s.Put(1, []string{"a"}))
+	as.Nil(s.Put(2, []string{"b"}))
+	as.Nil(s.Put(3, []string{"c"}))
+	as.Equal(2, s.Len())
+	_, ok, _ := s.Get(1)
+	as.False(ok)
+}
+
+func TestBoundedErrors(t *testing.T) {
+	as := assert.New(t)
+
+	s, err := store.NewBounded[string, any](store.Limits{}, 0)
+	as.Nil(s)
+	as.EqualError(err, store.ErrLimitRequired)
+
+	l := store.Limits{Rows: -1}
+	s, err = store.NewBounded[string, any](l, 0)
+	as.Nil(s)
+	as.EqualError(err, fmt.Sprintf(store.ErrInvalidLimits, l))
+
+	s, err = store.NewBounded[string, any](store.Limits{Rows: 1}, 99)
+	as.Nil(s)
+	as.EqualError(err, fmt.Sprintf(store.ErrInvalidPolicy, 99))
+}
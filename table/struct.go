@@ -0,0 +1,72 @@
+package table
+
+import "fmt"
+
+type (
+	// StructTable is a Table whose rows are values of the struct type Row,
+	// which may also be a pointer to a struct. Its Columns are derived from
+	// the struct's exported fields, named by their `table` tags if they have
+	// them. The Table's untyped Getters, Setters, and Updaters still work,
+	// using the same ColumnNames
+	StructTable[Key comparable, Row any] interface {
+		Table[Key, any]
+
+		// Get retrieves the row of the Key as a Row
+		Get(Key) (Row, error)
+
+		// Put writes every field of the Row to the row of the Key
+		Put(Key, Row) error
+	}
+
+	// Field is a Column backed by a field of the struct type Row, whose
+	// Values are of type T. Its Value method is a ValueSelector that can be
+	// passed to column.Make
+	Field[Row, T any] interface {
+		Column[Row, any]
+
+		// Value returns the field's Value in the Row. The zero Value is
+		// returned for a nil pointer Row
+		Value(Row) T
+
+		// Assign sets the field in the Row to the provided Value. A nil
+		// Value sets the field to its zero Value
+		Assign(*Row, any) error
+	}
+
+	// FieldGetter is a function that is capable of retrieving the typed
+	// Value of a single Field from a StructTable based on the provided Key
+	FieldGetter[Key comparable, T any] func(Key) (T, error)
+)
+
+// Error messages
+const (
+	ErrStructRequired = "row type must be a struct or pointer to struct: %s"
+	ErrFieldNotFound  = "field not found in struct: %s"
+	ErrFieldType      = "field %s is of type %s, not %s"
+	ErrFieldValue     = "value of type %T can't be assigned to field %s"
+)
+
+// GetField creates a FieldGetter for the provided Field of a StructTable
+func GetField[Key comparable, Row, T any](
+	t StructTable[Key, Row], f Field[Row, T],
+) (FieldGetter[Key, T], error) {
+	get, err := t.Getter(f.Name())
+	if err != nil {
+		return nil, err
+	}
+	return func(k Key) (T, error) {
+		var zero T
+		res, err := get(k)
+		if err != nil {
+			return zero, err
+		}
+		switch v := res[0].(type) {
+		case T:
+			return v, nil
+		case nil:
+			return zero, nil
+		default:
+			return zero, fmt.Errorf(ErrFieldValue, v, f.Name())
+		}
+	}, nil
+}
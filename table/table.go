@@ -1,5 +1,11 @@
 package table
 
+import (
+	"io"
+
+	"github.com/caravan/essentials/topic"
+)
+
 type (
 	// Table is an interface that associates a Key with multiple named Columns.
 	// The Key and Columns are selected using an Updater. Multiple Updaters are
@@ -11,8 +17,81 @@ type (
 		// Getter creates a Getter based on the specified ColumnNames.
 		Getter(...ColumnName) (Getter[Key, Value], error)
 
+		// MultiGetter creates a MultiGetter based on the specified
+		// ColumnNames
+		MultiGetter(...ColumnName) (MultiGetter[Key, Value], error)
+
+		// PresenceGetter creates a PresenceGetter based on the specified
+		// ColumnNames
+		PresenceGetter(...ColumnName) (PresenceGetter[Key, Value], error)
+
 		// Setter creates a Setter based on the specified ColumnNames.
 		Setter(...ColumnName) (Setter[Key, Value], error)
+
+		// VersionedGetter creates a VersionedGetter based on the specified
+		// ColumnNames
+		VersionedGetter(...ColumnName) (VersionedGetter[Key, Value], error)
+
+		// CompareAndSetter creates a CompareAndSetter based on the specified
+		// ColumnNames
+		CompareAndSetter(...ColumnName) (CompareAndSetter[Key, Value], error)
+
+		// AddColumn adds a column to this Table. Every existing row holds
+		// the provided Value in the new column, as does every new row until
+		// the column is set. Existing closures remain valid
+		AddColumn(ColumnName, Value) error
+
+		// DropColumn removes a column, and the Values it holds, from this
+		// Table. Closures that reference the column report an error when
+		// they're called. A column that's part of an index can't be dropped
+		DropColumn(ColumnName) error
+
+		// Deleter creates a Deleter for removing rows from this Table
+		Deleter() Deleter[Key]
+
+		// Finder creates a Finder that uses the secondary index declared on
+		// the first set of ColumnNames, retrieving the second set of
+		// ColumnNames
+		Finder(index []ColumnName, c ...ColumnName) (Finder[Key, Value], error)
+
+		// Scanner creates a Scanner based on the specified ColumnNames
+		Scanner(...ColumnName) (Scanner[Key, Value], error)
+
+		// Keys returns the Keys of every row currently in this Table, in no
+		// particular order
+		Keys() ([]Key, error)
+
+		// Len returns the number of rows currently in this Table
+		Len() int
+
+		// Stats reports the size of this Table and how it has been used
+		// since it was created
+		Stats() (Stats, error)
+
+		// Changes returns a Consumer that receives every Change made to this
+		// Table from this point on. The Consumer must be closed once it is no
+		// longer needed. Closing the Table closes the Consumer too, so that
+		// its channel ends
+		Changes() topic.Consumer[*Change[Key, Value]]
+
+		// Snapshot writes a consistent point-in-time copy of this Table's
+		// rows to the provided Writer, using the Table's configured Codec
+		Snapshot(io.Writer) error
+
+		// Begin starts a Transaction on this Table, returning its Txn. The
+		// Table is locked until the Transaction is committed or rolled back
+		Begin() Txn[Key, Value]
+
+		// Join adds this Table to an existing Transaction, so that its writes
+		// are committed together with those of the Transaction's other Tables
+		Join(Transaction) (Txn[Key, Value], error)
+
+		// Close flushes this Table's changelog and releases the resources it
+		// holds. A closed Table must no longer be written to. Closing a Table
+		// more than once has no further effect. Close also reports the first
+		// failure of the work that this Table does in the background, such
+		// as expiring rows or taking automatic snapshots
+		Close() error
 	}
 
 	// ColumnName is exactly what you think it is
@@ -22,9 +101,27 @@ type (
 	// column Values from a Table based on the provided Key
 	Getter[Key comparable, Value any] func(Key) ([]Value, error)
 
+	// MultiGetter is a function that is capable of retrieving a pre-defined
+	// set of column Values from a Table for many Keys at once, while only
+	// acquiring the Table's lock once. The rows that are found are returned
+	// by Key, and the Keys that aren't are returned in the order requested
+	MultiGetter[Key comparable, Value any] func(...Key) (
+		map[Key][]Value, []Key, error,
+	)
+
 	// Setter is a function that is capable of updating a pre-defined set of
 	// column Values in a Table based on the provided Key
 	Setter[Key comparable, Value any] func(Key, ...Value) error
+
+	// Finder is a function that is capable of retrieving the Keys and a
+	// pre-defined set of column Values of every row whose indexed columns
+	// match the provided Values. Rows are returned in no particular order
+	Finder[Key comparable, Value any] func(...Value) ([]Key, [][]Value, error)
+
+	// Deleter is a function that is capable of removing an entire row from a
+	// Table based on the provided Key. Deleting a Key that has no row does
+	// nothing
+	Deleter[Key comparable] func(Key) error
 )
 
 // Error messages
@@ -33,4 +130,10 @@ const (
 	ErrColumnNotFound      = "column not found in table: %s"
 	ErrDuplicateColumnName = "column name duplicated in table: %s"
 	ErrValueCountRequired  = "%d values are required, you provided %d"
+	ErrIndexNotFound       = "no index declared on columns: %v"
+	ErrIndexValue          = "value can't be indexed: %v"
+	ErrIndexValueNotFound  = "value not found in index: %v"
+	ErrColumnDropped       = "column dropped from table: %s"
+	ErrColumnIndexed       = "column is part of an index: %s"
+	ErrSchemaChangelog     = "schema of a table with a changelog can't change"
 )
@@ -0,0 +1,48 @@
+package table
+
+type (
+	// Transaction groups the writes made to one or more Tables, so that they
+	// are applied all at once or not at all. A Transaction holds exclusive
+	// access to its Tables until it's committed or rolled back, so readers
+	// never observe a partially applied Transaction. Because of this, a
+	// Transaction's Tables must only be accessed through its Txns, and
+	// transactions that share Tables must join them in the same order. A
+	// Transaction that's abandoned without being finished locks its Tables
+	// for good, so Rollback should be deferred as soon as it's begun.
+	//
+	// Each Table's changelog records its part of a Transaction on its own.
+	// If the process stops while a Transaction is being committed, each
+	// Table recovers either all of its part or none of it, but the Tables
+	// may disagree about whether the Transaction was committed
+	Transaction interface {
+		// Commit applies every write made through the Transaction's Txns
+		Commit() error
+
+		// Rollback discards every write made through the Transaction's
+		// Txns. It does nothing once the Transaction has been committed, so
+		// that it can be deferred
+		Rollback()
+	}
+
+	// Txn is a Transaction's view of a single Table. Its Getters see the
+	// writes made by its Setters and Deleter, but those writes only become
+	// visible to others once the Transaction is committed
+	Txn[Key comparable, Value any] interface {
+		Transaction
+
+		// Getter creates a Getter based on the specified ColumnNames
+		Getter(...ColumnName) (Getter[Key, Value], error)
+
+		// Setter creates a Setter based on the specified ColumnNames
+		Setter(...ColumnName) (Setter[Key, Value], error)
+
+		// Deleter creates a Deleter for removing rows from the Table
+		Deleter() Deleter[Key]
+	}
+)
+
+// Error messages
+const (
+	ErrTransactionFinished = "transaction already committed or rolled back"
+	ErrTransactionType     = "transaction can't be joined by this table: %T"
+)
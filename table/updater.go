@@ -14,7 +14,9 @@ type (
 		Columns() []Column[Msg, Value]
 
 		// Update extracts a Key and Column Values from a message and updates
-		// the associated Table
+		// the associated Table. If the Updater was created with a Tombstone
+		// that identifies the message, the row associated with the Key is
+		// deleted instead
 		Update(Msg) error
 	}
 
@@ -30,4 +32,9 @@ type (
 
 	// ValueSelector is used to extract a Value from a message
 	ValueSelector[Msg, Value any] func(Msg) Value
+
+	// Tombstone is used to identify messages that mark their Key as deleted.
+	// Returning true will cause an Updater to delete the Key's row rather
+	// than update it
+	Tombstone[Msg any] func(Msg) bool
 )
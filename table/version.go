@@ -0,0 +1,43 @@
+package table
+
+import "fmt"
+
+type (
+	// Version identifies a single write to a row. Versions increase with
+	// every write made to a row and are never reused by a Table, so a row
+	// that's deleted and inserted again won't reuse an earlier Version. The
+	// zero Version identifies a row that doesn't exist. Versions aren't
+	// persisted, and start over when a Table is restored
+	Version uint64
+
+	// VersionedGetter is a function that is capable of retrieving a
+	// pre-defined set of column Values from a Table, along with the row's
+	// current Version, based on the provided Key
+	VersionedGetter[Key comparable, Value any] func(Key) ([]Value, Version, error)
+
+	// CompareAndSetter is a function that is capable of updating a
+	// pre-defined set of column Values in a Table based on the provided Key,
+	// but only if the row's current Version is the one provided. Passing the
+	// zero Version only succeeds if the row doesn't exist. The row's new
+	// Version is returned
+	CompareAndSetter[Key comparable, Value any] func(
+		Key, Version, ...Value,
+	) (Version, error)
+
+	// VersionConflict is the error returned by a CompareAndSetter when the
+	// row's Version isn't the expected one
+	VersionConflict struct {
+		Key      any
+		Expected Version
+		Found    Version
+	}
+)
+
+// Error messages
+const (
+	ErrVersionConflict = "version conflict for key %v: expected %d, found %d"
+)
+
+func (e *VersionConflict) Error() string {
+	return fmt.Sprintf(ErrVersionConflict, e.Key, e.Expected, e.Found)
+}
@@ -0,0 +1,44 @@
+package table
+
+type (
+	// View is a Table whose rows are derived from other sources, such as
+	// Tables or Topics, and are kept up to date as those sources change.
+	// Updates are applied in the background, so a View briefly lags behind
+	// its sources. A View's rows must only be written by the View itself.
+	// Closing a View stops it from following its sources, and then closes
+	// its Table. A View that can't apply a Change from one of its sources
+	// stops following them, and reports why through Err
+	View[Key comparable, Value any] interface {
+		Table[Key, Value]
+
+		// IsClosed returns a channel that's closed once the View has stopped
+		// following its sources
+		IsClosed() <-chan struct{}
+
+		// Err returns the error that made the View stop following its
+		// sources, or nil if it's still following them or was closed
+		Err() error
+	}
+
+	// JoinType determines which rows of a joined View exist when one of its
+	// sources has no matching row
+	JoinType uint8
+)
+
+// Join types
+const (
+	// InnerJoin only derives a row when both sources have a matching row
+	InnerJoin JoinType = iota
+
+	// LeftJoin derives a row for every row of the left source. The right
+	// columns of a row without a match hold the zero Value
+	LeftJoin
+)
+
+// Error messages
+const (
+	ErrInvalidJoinType = "invalid join type: %d"
+	ErrForeignKeyType  = "foreign key is of the wrong type: %T"
+	ErrSourceClosed    = "view source was closed"
+	ErrTopicDiscarded  = "topic has discarded messages the view needs"
+)